@@ -2,41 +2,247 @@ package reqpretty
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/1saifj/reqpretty/pkg/printer"
 )
 
-// LogHandler is a slog.Handler implementation that can be used to log to a file.
+// LogHandler is a slog.Handler that renders records with the same boxes and
+// tables as the middleware: a level-colored box for the message, the
+// attributes as a table, every group as its own section and JSON-valued
+// attributes pretty-printed as bodies. Each record is written atomically.
 type LogHandler struct {
-	handler slog.Handler
+	printer *printer.ConsolePrinter
+	opts    slog.HandlerOptions
+
+	// groups are the open groups from WithGroup, attrs the attributes bound
+	// by WithAttrs along with the groups open at the time
+	groups []string
+	attrs  []boundAttr
+}
+
+// boundAttr is an attribute added through WithAttrs
+type boundAttr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+// NewHandler creates a new LogHandler writing to w, stdout when nil.
+// ReplaceAttr in opts is applied to the attributes of each record and to the
+// built-in time, level, message and source, which it can remove by
+// returning an empty Attr.
+func NewHandler(w io.Writer, opts *slog.HandlerOptions) *LogHandler {
+	h := &LogHandler{printer: printer.NewConsolePrinterWithWriter(w)}
+	if w == nil {
+		h.printer = printer.NewConsolePrinter()
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (l *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if l.opts.Level != nil {
+		minLevel = l.opts.Level.Level()
+	}
+	return level >= minLevel
 }
 
-// NewHandler creates a new LogHandler.
-func NewHandler(handler slog.Handler) LogHandler {
-	if handler == nil {
-		handler = slog.Default().Handler()
+func (l *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var layout recordLayout
+	for _, b := range l.attrs {
+		layout.add(b.groups, b.attr, l.opts.ReplaceAttr)
 	}
-	return LogHandler{
-		handler: handler,
+	record.Attrs(func(attr slog.Attr) bool {
+		layout.add(l.groups, attr, l.opts.ReplaceAttr)
+		return true
+	})
+
+	var title, details []string
+	if level, ok := l.builtin(slog.Any(slog.LevelKey, record.Level)); ok {
+		title = append(title, level)
+	}
+	if msg, ok := l.builtin(slog.String(slog.MessageKey, record.Message)); ok {
+		title = append(title, msg)
+	}
+	header := strings.Join(title, " - ")
+	if !record.Time.IsZero() {
+		if t, ok := l.builtin(slog.Time(slog.TimeKey, record.Time)); ok {
+			details = append(details, t)
+		}
 	}
+	if l.opts.AddSource && record.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{record.PC})
+		frame, _ := frames.Next()
+		src := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+		if s, ok := l.builtin(slog.Any(slog.SourceKey, src)); ok {
+			details = append(details, s)
+		}
+	}
+
+	l.printer.Batch(func(p printer.BoxPrinter) {
+		p.PrintBox(header, strings.Join(details, "\n"), levelColor(record.Level))
+		for _, s := range layout.sections {
+			p.PrintTable(s.values, s.name)
+		}
+		for _, b := range layout.bodies {
+			p.PrintBody(b.body, b.name)
+		}
+	})
+	return nil
 }
 
-func (l LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return l.handler.Enabled(ctx, level)
+// builtin applies ReplaceAttr to a built-in attribute of a record and
+// returns its text, false when it was removed
+func (l *LogHandler) builtin(attr slog.Attr) (string, bool) {
+	if l.opts.ReplaceAttr != nil {
+		attr = l.opts.ReplaceAttr(nil, attr)
+	}
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return "", false
+	}
+	switch v := attr.Value.Any().(type) {
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.000"), true
+	case *slog.Source:
+		return fmt.Sprintf("%s:%d", v.File, v.Line), true
+	default:
+		return attr.Value.String(), true
+	}
 }
 
-func (l LogHandler) Handle(ctx context.Context, record slog.Record) error {
-	// Customize the logging format here
-	return l.handler.Handle(ctx, record)
+func (l *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return l
+	}
+	h := *l
+	h.attrs = slices.Clip(h.attrs)
+	for _, attr := range attrs {
+		h.attrs = append(h.attrs, boundAttr{groups: l.groups, attr: attr})
+	}
+	return &h
 }
 
-func (l LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return LogHandler{
-		handler: l.handler.WithAttrs(attrs),
+func (l *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return l
 	}
+	h := *l
+	h.groups = append(slices.Clip(l.groups), name)
+	return &h
 }
 
-func (l LogHandler) WithGroup(name string) slog.Handler {
-	return LogHandler{
-		handler: l.handler.WithGroup(name),
+// levelColor picks the box color for a record level
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "red"
+	case level >= slog.LevelWarn:
+		return "yellow"
+	case level >= slog.LevelInfo:
+		return "green"
+	default:
+		return "cyan"
+	}
+}
+
+// recordLayout sorts the attributes of a record into sections, one per
+// group, and JSON bodies
+type recordLayout struct {
+	sections []*section
+	bodies   []jsonAttr
+}
+
+// section is the table of attributes sharing a group
+type section struct {
+	name   string
+	values map[string]interface{}
+}
+
+// jsonAttr is an attribute holding a JSON document
+type jsonAttr struct {
+	name string
+	body []byte
+}
+
+// add places attr, nested in groups, into its section or the bodies
+func (l *recordLayout) add(groups []string, attr slog.Attr, replace func([]string, slog.Attr) slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if replace != nil && attr.Value.Kind() != slog.KindGroup {
+		attr = replace(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		// Attributes of a group without a key are inlined
+		if attr.Key != "" {
+			groups = append(slices.Clip(groups), attr.Key)
+		}
+		for _, child := range attr.Value.Group() {
+			l.add(groups, child, replace)
+		}
+		return
+	}
+
+	if body, ok := jsonValue(attr.Value); ok {
+		name := strings.Join(append(slices.Clip(groups), attr.Key), ".")
+		l.bodies = append(l.bodies, jsonAttr{name: name, body: body})
+		return
+	}
+	l.section(groups).values[attr.Key] = attr.Value.Any()
+}
+
+// section returns the section of a group path, adding it on first use
+func (l *recordLayout) section(groups []string) *section {
+	name := "Attributes"
+	if len(groups) > 0 {
+		name = strings.Join(groups, ".")
+	}
+	for _, s := range l.sections {
+		if s.name == name {
+			return s
+		}
+	}
+	s := &section{name: name, values: make(map[string]interface{})}
+	l.sections = append(l.sections, s)
+	return s
+}
+
+// jsonValue returns the document held by a string, []byte or
+// json.RawMessage attribute that contains a JSON object or array
+func jsonValue(v slog.Value) ([]byte, bool) {
+	var b []byte
+	switch v.Kind() {
+	case slog.KindString:
+		b = []byte(v.String())
+	case slog.KindAny:
+		switch raw := v.Any().(type) {
+		case json.RawMessage:
+			b = raw
+		case []byte:
+			b = raw
+		default:
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	trimmed := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
 	}
+	return b, json.Valid(b)
 }
@@ -7,8 +7,10 @@ import (
 
 // ANSI color codes
 const (
-	Reset = "\033[0m"
-	Bold  = "\033[1m"
+	Reset     = "\033[0m"
+	Bold      = "\033[1m"
+	Faint     = "\033[2m"
+	Underline = "\033[4m"
 
 	// Colors
 	Black   = "\033[30m"
@@ -84,8 +86,10 @@ func (s Style) Render(text string) string {
 		result = s.color + result
 	}
 
-	// Add reset at the end
-	result += Reset
+	// Add reset at the end, unstyled text needs none
+	if s.bold || s.color != "" {
+		result += Reset
+	}
 
 	// Apply padding
 	if s.padding > 0 {
@@ -106,7 +110,7 @@ func (s Style) Render(text string) string {
 }
 
 func (s Style) addBorder(text string) string {
-	lines := strings.Split(text, "\n")
+	lines := strings.Split(ExpandTabs(text), "\n")
 	if len(lines) == 0 {
 		return text
 	}
@@ -114,9 +118,8 @@ func (s Style) addBorder(text string) string {
 	// Calculate the width of the box
 	maxWidth := 0
 	for _, line := range lines {
-		cleanLine := removeAnsiCodes(line)
-		if len(cleanLine) > maxWidth {
-			maxWidth = len(cleanLine)
+		if w := Width(line); w > maxWidth {
+			maxWidth = w
 		}
 	}
 
@@ -129,8 +132,7 @@ func (s Style) addBorder(text string) string {
 
 	// Content lines
 	for _, line := range lines {
-		cleanLine := removeAnsiCodes(line)
-		padding := maxWidth - len(cleanLine)
+		padding := maxWidth - Width(line)
 		paddedLine := Vertical + line + strings.Repeat(" ", padding) + Vertical
 		result = append(result, paddedLine)
 	}
@@ -142,25 +144,6 @@ func (s Style) addBorder(text string) string {
 	return strings.Join(result, "\n")
 }
 
-func removeAnsiCodes(text string) string {
-	result := ""
-	inEscape := false
-	for i, char := range text {
-		if char == '\033' && i+1 < len(text) && text[i+1] == '[' {
-			inEscape = true
-			continue
-		}
-		if inEscape && (char == 'm' || char == 'K' || char == 'J') {
-			inEscape = false
-			continue
-		}
-		if !inEscape {
-			result += string(char)
-		}
-	}
-	return result
-}
-
 // Helper functions for common styles
 func ColoredText(text, color string) string {
 	return color + text + Reset
@@ -0,0 +1,220 @@
+package styles
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TabWidth is the distance between tab stops
+const TabWidth = 8
+
+// Width returns the number of terminal cells text occupies on a single
+// line. ANSI escape sequences and zero-width characters take no space, East
+// Asian wide characters and emoji take two cells and tabs advance to the
+// next tab stop.
+func Width(text string) int {
+	var m measure
+	for i := 0; i < len(text); {
+		if n := escapeLen(text[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		m.advance(r)
+	}
+	return m.col
+}
+
+// measure tracks the position on a line while text is measured one rune
+// at a time
+type measure struct {
+	col         int
+	prevWidth   int  // width of the previous visible rune
+	joined      bool // the previous rune was a zero width joiner
+	pendingFlag bool // an unpaired regional indicator was seen
+}
+
+// advance moves past r, returning the cells it adds and whether it belongs
+// to the glyph before it, such as combining marks and emoji modifiers
+func (m *measure) advance(r rune) (width int, attached bool) {
+	switch {
+	case r == '\t':
+		width = TabWidth - m.col%TabWidth
+		m.prevWidth, m.joined, m.pendingFlag = 0, false, false
+	case r == '\u200d':
+		m.joined = true
+		attached = true
+	case r == '\ufe0f':
+		// Emoji presentation selector widens the preceding symbol
+		if m.prevWidth == 1 {
+			width = 1
+			m.prevWidth = 2
+		}
+		attached = true
+	case isZeroWidth(r):
+		attached = true
+	case isSkinTone(r) && m.prevWidth == 2:
+		// Skin tone modifiers merge into the preceding emoji
+		attached = true
+	case m.joined:
+		// Part of an emoji ZWJ sequence drawn as one glyph
+		m.joined = false
+		attached = true
+	case isRegionalIndicator(r):
+		// Pairs of regional indicators form a single flag
+		if m.pendingFlag {
+			m.pendingFlag = false
+			attached = true
+			break
+		}
+		m.pendingFlag = true
+		width = 2
+		m.prevWidth = 2
+	default:
+		width = runeWidth(r)
+		m.prevWidth, m.pendingFlag = width, false
+	}
+	m.col += width
+	return width, attached
+}
+
+// ExpandTabs replaces tabs in text with spaces up to the next tab stop, so
+// the text keeps its layout wherever it is placed on a line
+func ExpandTabs(text string) string {
+	if !strings.Contains(text, "\t") {
+		return text
+	}
+	var b strings.Builder
+	col := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		col = 0
+		start := 0
+		for i := 0; i < len(line); i++ {
+			if line[i] != '\t' {
+				continue
+			}
+			b.WriteString(line[start:i])
+			col += Width(line[start:i])
+			spaces := TabWidth - col%TabWidth
+			b.WriteString(strings.Repeat(" ", spaces))
+			col += spaces
+			start = i + 1
+		}
+		b.WriteString(line[start:])
+	}
+	return b.String()
+}
+
+// StripANSI removes ANSI escape sequences from text
+func StripANSI(text string) string {
+	if !strings.Contains(text, "\033") {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		if n := escapeLen(text[i:]); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(text[i])
+		i++
+	}
+	return b.String()
+}
+
+// escapeLen returns the length of the ANSI escape sequence text starts
+// with, or zero. CSI sequences end with a byte in 0x40-0x7E, OSC sequences
+// such as hyperlinks with BEL or ESC \.
+func escapeLen(text string) int {
+	if len(text) < 2 || text[0] != '\033' {
+		return 0
+	}
+	switch text[1] {
+	case '[':
+		for i := 2; i < len(text); i++ {
+			if text[i] >= 0x40 && text[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(text)
+	case ']':
+		for i := 2; i < len(text); i++ {
+			if text[i] == '\a' {
+				return i + 1
+			}
+			if text[i] == '\033' && i+1 < len(text) && text[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(text)
+	default:
+		return 2
+	}
+}
+
+// isZeroWidth reports whether r takes no space on its own: combining
+// marks, format characters such as joiners, and control characters
+func isZeroWidth(r rune) bool {
+	return r < 0x20 || (r >= 0x7f && r < 0xa0) ||
+		unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) ||
+		(r >= 0x1160 && r <= 0x11ff) // Hangul medial vowels and final consonants
+}
+
+func isSkinTone(r rune) bool {
+	return r >= 0x1f3fb && r <= 0x1f3ff
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// runeWidth returns 2 for East Asian wide and fullwidth characters and
+// emoji with a default emoji presentation, 1 otherwise
+func runeWidth(r rune) int {
+	if r < 0x1100 {
+		return 1
+	}
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wideRanges[mid][0]:
+			hi = mid
+		case r > wideRanges[mid][1]:
+			lo = mid + 1
+		default:
+			return 2
+		}
+	}
+	return 1
+}
+
+// wideRanges lists the wide (W) and fullwidth (F) code points of Unicode
+// East Asian Width, which includes the emoji presented as pictographs
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec},
+	{0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0}, {0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18aff}, {0x1b000, 0x1b2ff}, {0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f202}, {0x1f210, 0x1f23b},
+	{0x1f240, 0x1f248}, {0x1f250, 0x1f251}, {0x1f260, 0x1f265}, {0x1f300, 0x1f320},
+	{0x1f32d, 0x1f335}, {0x1f337, 0x1f37c}, {0x1f37e, 0x1f393}, {0x1f3a0, 0x1f3ca},
+	{0x1f3cf, 0x1f3d3}, {0x1f3e0, 0x1f3f0}, {0x1f3f4, 0x1f3f4}, {0x1f3f8, 0x1f43e},
+	{0x1f440, 0x1f440}, {0x1f442, 0x1f4fc}, {0x1f4ff, 0x1f53d}, {0x1f54b, 0x1f54e},
+	{0x1f550, 0x1f567}, {0x1f57a, 0x1f57a}, {0x1f595, 0x1f596}, {0x1f5a4, 0x1f5a4},
+	{0x1f5fb, 0x1f64f}, {0x1f680, 0x1f6c5}, {0x1f6cc, 0x1f6cc}, {0x1f6d0, 0x1f6d2},
+	{0x1f6d5, 0x1f6d7}, {0x1f6dc, 0x1f6df}, {0x1f6eb, 0x1f6ec}, {0x1f6f4, 0x1f6fc},
+	{0x1f7e0, 0x1f7eb}, {0x1f7f0, 0x1f7f0}, {0x1f90c, 0x1f93a}, {0x1f93c, 0x1f945},
+	{0x1f947, 0x1f9ff}, {0x1fa70, 0x1faff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
@@ -0,0 +1,100 @@
+package styles
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Wrap splits a single line into lines of at most width cells, breaking
+// between glyphs. Continuation lines start with marker, which counts
+// towards the width and is left out when it leaves no room for a wide
+// glyph. Styles that are active at a break are closed with a
+// reset and every sequence since the last reset is replayed on the next
+// line.
+func Wrap(line string, width int, marker string) []string {
+	if width <= 0 || Width(line) <= width {
+		return []string{line}
+	}
+	markerWidth := Width(marker)
+	if markerWidth+2 > width {
+		marker, markerWidth = "", 0
+	}
+
+	var (
+		lines  []string
+		cur    strings.Builder
+		m      measure
+		active string // the style sequences since the last reset
+	)
+	for i := 0; i < len(line); {
+		if n := escapeLen(line[i:]); n > 0 {
+			seq := line[i : i+n]
+			if seq == Reset || seq == "\033[m" {
+				active = ""
+			} else if strings.HasSuffix(seq, "m") {
+				active += seq
+			}
+			cur.WriteString(seq)
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		before := m
+		w, attached := m.advance(r)
+		if !attached && w > 0 && before.col > 0 && m.col > width {
+			if active != "" {
+				cur.WriteString(Reset)
+			}
+			lines = append(lines, cur.String())
+			cur.Reset()
+			cur.WriteString(marker)
+			cur.WriteString(active)
+
+			// Measure the rune again at the start of the continuation line
+			m = measure{col: markerWidth}
+			m.advance(r)
+		}
+		cur.WriteString(line[i : i+size])
+		i += size
+	}
+	return append(lines, cur.String())
+}
+
+// Truncate shortens text to at most width cells, ending it with tail when
+// anything was cut. A tail wider than width is cut itself. A color still
+// active at the cut is reset.
+func Truncate(text string, width int, tail string) string {
+	if width <= 0 || Width(text) <= width {
+		return text
+	}
+	limit := width - Width(tail)
+	if limit < 0 {
+		return Truncate(tail, width, "")
+	}
+
+	var (
+		cur     strings.Builder
+		m       measure
+		colored bool
+	)
+	for i := 0; i < len(text); {
+		if n := escapeLen(text[i:]); n > 0 {
+			seq := text[i : i+n]
+			colored = seq != Reset && seq != "\033[m"
+			cur.WriteString(seq)
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if w, attached := m.advance(r); !attached && w > 0 && m.col > limit {
+			break
+		}
+		cur.WriteString(text[i : i+size])
+		i += size
+	}
+	if colored {
+		cur.WriteString(Reset)
+	}
+	cur.WriteString(tail)
+	return cur.String()
+}
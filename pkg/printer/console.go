@@ -2,31 +2,41 @@ package printer
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
+	"sync"
+
+	"github.com/1saifj/reqpretty/internal/styles"
 )
 
 // ANSI color codes and styling
 const (
-	Reset = "\033[0m"
-	Bold  = "\033[1m"
+	Reset     = styles.Reset
+	Bold      = styles.Bold
+	Faint     = styles.Faint
+	Underline = styles.Underline
 
 	// Colors
-	Blue    = "\033[34m"
-	Green   = "\033[32m"
-	Red     = "\033[31m"
-	Yellow  = "\033[33m"
-	Magenta = "\033[35m"
-	Cyan    = "\033[36m"
-	White   = "\033[37m"
+	Blue    = styles.Blue
+	Green   = styles.Green
+	Red     = styles.Red
+	Yellow  = styles.Yellow
+	Magenta = styles.Magenta
+	Cyan    = styles.Cyan
+	White   = styles.White
 
 	// Bright colors
-	BrightBlue   = "\033[94m"
-	BrightGreen  = "\033[92m"
-	BrightRed    = "\033[91m"
-	BrightYellow = "\033[93m"
-	BrightCyan   = "\033[96m"
+	BrightBlack   = styles.BrightBlack
+	BrightBlue    = styles.BrightBlue
+	BrightGreen   = styles.BrightGreen
+	BrightRed     = styles.BrightRed
+	BrightYellow  = styles.BrightYellow
+	BrightMagenta = styles.BrightMagenta
+	BrightCyan    = styles.BrightCyan
+	BrightWhite   = styles.BrightWhite
 
 	// Box drawing characters
 	TopLeft     = "┌"
@@ -40,44 +50,161 @@ const (
 )
 
 // ConsolePrinter implements Printer interface for console output
-type ConsolePrinter struct{}
+type ConsolePrinter struct {
+	out io.Writer
+	mu  *sync.Mutex
+
+	// noColor drops the ANSI escapes, box holds the glyphs and theme the
+	// colors, the zero values draw Unicode boxes in DarkTheme
+	noColor bool
+	box     BoxStyle
+	theme   Theme
+
+	// maxWidth limits the output width, zero uses the terminal width when
+	// writing to a terminal and no limit otherwise
+	maxWidth int
+}
 
-// NewConsolePrinter creates a new console printer
+// NewConsolePrinter creates a new console printer that writes to stdout
 func NewConsolePrinter() *ConsolePrinter {
-	return &ConsolePrinter{}
+	return NewConsolePrinterWithWriter(os.Stdout)
+}
+
+// NewConsolePrinterWithWriter creates a new console printer that writes to
+// w, with colors only when w is a terminal (see ColorAuto)
+func NewConsolePrinterWithWriter(w io.Writer) *ConsolePrinter {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &ConsolePrinter{out: w, mu: &sync.Mutex{}, noColor: !colorEnabled(ColorAuto, w)}
+}
+
+// WithColorMode returns a copy of the printer using mode, sharing its
+// output and lock
+func (p *ConsolePrinter) WithColorMode(mode ColorMode) *ConsolePrinter {
+	c := *p
+	out := c.out
+	if out == nil {
+		out = os.Stdout
+	}
+	c.noColor = !colorEnabled(mode, out)
+	return &c
+}
+
+// WithBoxStyle returns a copy of the printer drawing boxes with style,
+// sharing its output and lock
+func (p *ConsolePrinter) WithBoxStyle(style BoxStyle) *ConsolePrinter {
+	c := *p
+	c.box = style
+	return &c
+}
+
+// WithTheme returns a copy of the printer coloring its output with theme,
+// sharing its output and lock
+func (p *ConsolePrinter) WithTheme(theme Theme) *ConsolePrinter {
+	c := *p
+	c.theme = theme
+	return &c
+}
+
+// WithMaxWidth returns a copy of the printer that never writes lines wider
+// than width cells, wrapping long lines and truncating table values. Widths
+// below MinWidth are raised to it, and zero restores the default of
+// following the terminal width.
+func (p *ConsolePrinter) WithMaxWidth(width int) *ConsolePrinter {
+	c := *p
+	c.maxWidth = width
+	return &c
+}
+
+// PrintExchange renders the exchange as boxes and tables in a single write
+func (p *ConsolePrinter) PrintExchange(e Exchange) {
+	p.Batch(func(bp BoxPrinter) {
+		renderExchange(bp, e)
+	})
+}
+
+// PrintStreamEvent renders a streamed event or chunk as soon as it is written
+func (p *ConsolePrinter) PrintStreamEvent(e StreamEvent) {
+	renderStreamEvent(p, e)
+}
+
+// PrintSummary renders the periodic sampling summary
+func (p *ConsolePrinter) PrintSummary(s Summary) {
+	renderSummary(p, s)
+}
+
+// Batch renders everything fn prints into a buffer and flushes it to the
+// output as a single write, followed by a blank separator line
+func (p *ConsolePrinter) Batch(fn func(BoxPrinter)) {
+	var buf bytes.Buffer
+	child := *p
+	child.out = &buf
+	child.mu = &sync.Mutex{}
+	child.maxWidth = p.width()
+
+	fn(&child)
+	fmt.Fprintln(&buf)
+	p.write(buf.Bytes())
+}
+
+// defaultMu guards writes from zero-value printers, which go to stdout
+var defaultMu sync.Mutex
+
+// write sends b to the output in one call while holding the lock
+func (p *ConsolePrinter) write(b []byte) {
+	out, mu := p.out, p.mu
+	if out == nil {
+		out = os.Stdout
+	}
+	if mu == nil {
+		mu = &defaultMu
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	out.Write(b)
 }
 
 // PrintBox prints text in a beautiful bordered box
 func (p *ConsolePrinter) PrintBox(header, text, color string) {
-	colorCode := p.getColorCode(color)
-	content := header
+	var b bytes.Buffer
+	content := p.text(header)
 	if text != "" {
-		content += "\n" + text
+		content += "\n" + p.text(text)
 	}
 
-	lines := strings.Split(content, "\n")
+	lines := p.wrapLines(boxLines(content))
 	maxWidth := 0
 	for _, line := range lines {
-		if len(line) > maxWidth {
-			maxWidth = len(line)
+		if w := styles.Width(line); w > maxWidth {
+			maxWidth = w
 		}
 	}
 
 	// Add padding
 	maxWidth += 4
 
+	p.writeBox(&b, lines, maxWidth, p.boxColor(color))
+	p.write(b.Bytes())
+}
+
+// writeBox draws lines inside a border colored with code, width cells wide
+func (p *ConsolePrinter) writeBox(b *bytes.Buffer, lines []string, width int, code string) {
+	g := p.glyphs()
+	vertical := p.paint(code, g.Vertical)
+
 	// Top border
-	fmt.Printf("%s%s%s%s%s\n", colorCode, TopLeft, strings.Repeat(Horizontal, maxWidth), TopRight, Reset)
+	fmt.Fprintln(b, p.paint(code, g.TopLeft+strings.Repeat(g.Horizontal, width)+g.TopRight))
 
 	// Content lines
 	for _, line := range lines {
-		padding := maxWidth - len(line) - 2
-		fmt.Printf("%s%s %s%s %s%s\n", colorCode, Vertical, Reset+line, strings.Repeat(" ", padding), colorCode, Vertical+Reset)
+		padding := width - styles.Width(line) - 2
+		fmt.Fprintf(b, "%s %s%s %s\n", vertical, line, strings.Repeat(" ", padding), vertical)
 	}
 
 	// Bottom border
-	fmt.Printf("%s%s%s%s%s\n", colorCode, BottomLeft, strings.Repeat(Horizontal, maxWidth), BottomRight, Reset)
-	fmt.Println()
+	fmt.Fprintln(b, p.paint(code, g.BottomLeft+strings.Repeat(g.Horizontal, width)+g.BottomRight))
+	fmt.Fprintln(b)
 }
 
 // PrintTable prints a map as a beautiful table
@@ -85,114 +212,126 @@ func (p *ConsolePrinter) PrintTable(data map[string]interface{}, header string)
 	if len(data) == 0 {
 		return
 	}
+	var b bytes.Buffer
+	g := p.glyphs()
+	t := p.palette()
 
 	// Print table header
-	fmt.Printf("%s%s%s %s %s%s\n", BrightCyan, Bold, header, Reset, BrightCyan, Reset)
+	fmt.Fprintf(&b, "%s \n", p.paint(t.TableTitle, p.title(header)+" "))
 
-	// Calculate max key width
-	maxKeyWidth := 0
-	for key := range data {
-		if len(key) > maxKeyWidth {
-			maxKeyWidth = len(key)
-		}
+	type row struct{ key, value string }
+	rows := make([]row, 0, len(data))
+	for key, value := range data {
+		rows = append(rows, row{key: tableCell(key), value: tableCell(fmt.Sprintf("%v", value))})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].key < rows[j].key })
+
+	// Calculate max key and value widths
+	maxKeyWidth, maxValueWidth := 0, 0
+	for _, r := range rows {
+		maxKeyWidth = max(maxKeyWidth, styles.Width(r.key))
+		maxValueWidth = max(maxValueWidth, styles.Width(r.value))
 	}
-	maxKeyWidth += 2 // Add padding
-
-	// Calculate max value width
-	maxValueWidth := 0
-	for _, value := range data {
-		valueStr := fmt.Sprintf("%v", value)
-		if len(valueStr) > maxValueWidth {
-			maxValueWidth = len(valueStr)
+
+	// Truncate keys and values that would make the table too wide, values
+	// give way first
+	if limit := p.width(); limit > 0 {
+		available := max(limit-7, 2) // Borders and padding
+		if maxKeyWidth+maxValueWidth > available {
+			keyLimit := max(min(maxKeyWidth, max(available-maxValueWidth, available/2)), 1)
+			valueLimit := max(available-keyLimit, 1)
+			for i, r := range rows {
+				rows[i].key = styles.Truncate(r.key, keyLimit, p.ellipsis())
+				rows[i].value = styles.Truncate(r.value, valueLimit, p.ellipsis())
+			}
+			maxKeyWidth = min(maxKeyWidth, keyLimit)
+			maxValueWidth = min(maxValueWidth, valueLimit)
 		}
 	}
+
+	maxKeyWidth += 2   // Add padding
 	maxValueWidth += 2 // Add padding
 
 	// Top border
-	fmt.Printf("%s%s%s%s%s%s\n",
-		BrightCyan, TopLeft,
-		strings.Repeat(Horizontal, maxKeyWidth),
-		TeeDown,
-		strings.Repeat(Horizontal, maxValueWidth),
-		TopRight+Reset)
+	fmt.Fprintln(&b, p.paint(t.TableBorder, g.TopLeft+
+		strings.Repeat(g.Horizontal, maxKeyWidth)+
+		g.TeeDown+
+		strings.Repeat(g.Horizontal, maxValueWidth)+
+		g.TopRight))
 
 	// Data rows
-	for key, value := range data {
-		valueStr := fmt.Sprintf("%v", value)
-		keyPadding := maxKeyWidth - len(key) - 1
-		valuePadding := maxValueWidth - len(valueStr) - 1
-
-		fmt.Printf("%s%s%s %s%s%s%s %s%s%s%s\n",
-			BrightCyan, Vertical, Reset,
-			key, strings.Repeat(" ", keyPadding),
-			BrightCyan, Vertical, Reset,
-			valueStr, strings.Repeat(" ", valuePadding),
-			BrightCyan, Vertical)
+	vertical := p.paint(t.TableBorder, g.Vertical)
+	for _, r := range rows {
+		keyPadding := maxKeyWidth - styles.Width(r.key) - 1
+		valuePadding := maxValueWidth - styles.Width(r.value) - 1
+
+		fmt.Fprintf(&b, "%s %s%s%s %s%s%s\n",
+			vertical,
+			p.paint(t.Key, r.key), strings.Repeat(" ", keyPadding),
+			vertical,
+			p.paint(t.Value, r.value), strings.Repeat(" ", valuePadding),
+			vertical)
 	}
 
 	// Bottom border
-	fmt.Printf("%s%s%s%s%s%s\n",
-		BrightCyan, BottomLeft,
-		strings.Repeat(Horizontal, maxKeyWidth),
-		TeeUp,
-		strings.Repeat(Horizontal, maxValueWidth),
-		BottomRight+Reset)
-	fmt.Println()
+	fmt.Fprintln(&b, p.paint(t.TableBorder, g.BottomLeft+
+		strings.Repeat(g.Horizontal, maxKeyWidth)+
+		g.TeeUp+
+		strings.Repeat(g.Horizontal, maxValueWidth)+
+		g.BottomRight))
+	fmt.Fprintln(&b)
+	p.write(b.Bytes())
+}
+
+// lineBreaks turns CRLF line endings into plain ones and escapes lone
+// carriage returns, which would move the cursor back over the border
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", `\r`)
+
+// boxLines splits text into the lines of a box
+func boxLines(text string) []string {
+	return strings.Split(styles.ExpandTabs(lineBreaks.Replace(text)), "\n")
+}
+
+// cellEscaper keeps line breaks from splitting a table row
+var cellEscaper = strings.NewReplacer("\r", `\r`, "\n", `\n`)
+
+// tableCell prepares a key or value for a single table row
+func tableCell(s string) string {
+	return styles.ExpandTabs(cellEscaper.Replace(s))
 }
 
 // PrintBody prints formatted body content
 func (p *ConsolePrinter) PrintBody(body []byte, header string) {
+	p.printBody(body, header, "")
+}
+
+// printBody prints formatted body content with an optional footer line
+func (p *ConsolePrinter) printBody(body []byte, header, footer string) {
 	formattedBody := p.formatBodyPretty(body)
+	var b bytes.Buffer
+	t := p.palette()
 
 	// Print header
-	fmt.Printf("%s%s%s %s %s%s\n", BrightYellow, Bold, header, Reset, BrightYellow, Reset)
+	fmt.Fprintf(&b, "%s \n", p.paint(t.BodyTitle, p.title(header)+" "))
 
-	lines := strings.Split(formattedBody, "\n")
+	lines := boxLines(formattedBody)
+	if footer != "" {
+		lines = append(lines, p.text(footer))
+	}
+	lines = p.wrapLines(lines)
 	maxWidth := 0
 	for _, line := range lines {
-		if len(line) > maxWidth {
-			maxWidth = len(line)
+		if w := styles.Width(line); w > maxWidth {
+			maxWidth = w
 		}
 	}
 	maxWidth += 4 // Add padding
 
-	// Top border
-	fmt.Printf("%s%s%s%s%s\n", BrightYellow, TopLeft, strings.Repeat(Horizontal, maxWidth), TopRight, Reset)
-
-	// Content lines
-	for _, line := range lines {
-		padding := maxWidth - len(line) - 2
-		fmt.Printf("%s%s %s%s %s%s\n", BrightYellow, Vertical, Reset+line, strings.Repeat(" ", padding), BrightYellow, Vertical+Reset)
-	}
-
-	// Bottom border
-	fmt.Printf("%s%s%s%s%s\n", BrightYellow, BottomLeft, strings.Repeat(Horizontal, maxWidth), BottomRight, Reset)
-	fmt.Println()
-}
-
-// getColorCode returns the ANSI color code for a color name
-func (p *ConsolePrinter) getColorCode(color string) string {
-	switch strings.ToLower(color) {
-	case "blue":
-		return BrightBlue
-	case "green":
-		return BrightGreen
-	case "red":
-		return BrightRed
-	case "yellow":
-		return BrightYellow
-	case "cyan":
-		return BrightCyan
-	default:
-		return White
-	}
+	p.writeBox(&b, lines, maxWidth, t.BodyBorder)
+	p.write(b.Bytes())
 }
 
-// formatBodyPretty formats the body for pretty printing
+// formatBodyPretty formats the body for pretty printing, highlighting JSON
 func (p *ConsolePrinter) formatBodyPretty(body []byte) string {
-	var prettyJSON bytes.Buffer
-	if err := json.Indent(&prettyJSON, body, "", "    "); err == nil {
-		return prettyJSON.String()
-	}
-	return string(body) // If not JSON, return as plain text
+	return highlightJSON(body, p.palette(), p.paint)
 }
@@ -0,0 +1,159 @@
+package printer
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Exchange describes a single request/response round trip
+type Exchange struct {
+	// Time is when the request was received
+	Time time.Time
+
+	// Duration is how long the exchange took
+	Duration time.Duration
+
+	// Request is nil when request logging is disabled
+	Request *Request
+
+	// Response is nil when response logging is disabled
+	Response *Response
+
+	// Panic is set when the handler panicked
+	Panic *Panic
+
+	// Outbound marks exchanges made by an http.Client rather than received
+	// by a server
+	Outbound bool
+
+	// Error is set when an outbound request failed before a complete
+	// response was received
+	Error *TransportError
+
+	// Timing breaks the duration down into phases when available
+	Timing *Timing
+
+	// RequestID correlates the request and response boxes with other logs,
+	// empty when request IDs are disabled
+	RequestID string
+
+	// TraceID and SpanID identify the distributed trace of the exchange,
+	// TraceURL links to it in a tracing UI
+	TraceID  string
+	SpanID   string
+	TraceURL string
+
+	// Attrs holds the attributes extracted from the request context
+	Attrs []slog.Attr
+
+	// Context is the request context, for printers that hand it on such as
+	// SlogPrinter
+	Context context.Context
+}
+
+// Request holds the logged parts of an HTTP request
+type Request struct {
+	Method  string
+	URL     string
+	Headers http.Header
+	Query   url.Values
+	Body    []byte
+
+	// BodySize is the total body size in bytes as sent
+	BodySize int64
+
+	// BodyTruncated reports that Body only holds a prefix of the body
+	BodyTruncated bool
+
+	// BodyRead reports how much of a streamed body the handler consumed
+	BodyRead BodyReadState
+}
+
+// BodyReadState describes how much of a streamed request body was consumed
+type BodyReadState int
+
+const (
+	// BodyReadUnknown means the body was captured up front, not streamed
+	BodyReadUnknown BodyReadState = iota
+	// BodyNotRead means the handler never read from the body
+	BodyNotRead
+	// BodyPartiallyRead means the handler stopped before the end of the body
+	BodyPartiallyRead
+	// BodyFullyRead means the handler read the body to the end
+	BodyFullyRead
+)
+
+// String returns a human readable description of the state
+func (s BodyReadState) String() string {
+	switch s {
+	case BodyNotRead:
+		return "not read"
+	case BodyPartiallyRead:
+		return "partially read"
+	case BodyFullyRead:
+		return "fully read"
+	default:
+		return "unknown"
+	}
+}
+
+// Response holds the logged parts of an HTTP response
+type Response struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+
+	// BodySize is the total body size in bytes as sent
+	BodySize int64
+
+	// BodyTruncated reports that Body only holds a prefix of the body
+	BodyTruncated bool
+
+	// Upgraded reports that the handler hijacked or upgraded the connection
+	Upgraded bool
+
+	// Stream is set when the response was streamed, the body was then
+	// printed as StreamEvents instead of being kept
+	Stream *StreamSummary
+
+	// Emoji marks the status in rendered output, a default is used when empty
+	Emoji string
+}
+
+// Timing is a waterfall of the phases of an exchange
+type Timing struct {
+	Phases []TimingPhase
+
+	// TimeToFirstByte is measured from the start of the exchange
+	TimeToFirstByte time.Duration
+
+	// Total is the duration from the start of the exchange to the last phase
+	Total time.Duration
+
+	// ConnReused reports whether an outbound request reused a pooled connection
+	ConnReused bool
+}
+
+// TimingPhase is one step of a Timing waterfall, Start is relative to the
+// start of the exchange
+type TimingPhase struct {
+	Name     string
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// TransportError describes an outbound request that failed in transit
+type TransportError struct {
+	// Kind is a short classification such as "DNS lookup failed" or "Timeout"
+	Kind string
+	Err  error
+}
+
+// Panic holds a recovered panic value and the stack at the point of recovery
+type Panic struct {
+	Value interface{}
+	Stack []byte
+}
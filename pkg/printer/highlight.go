@@ -0,0 +1,289 @@
+package printer
+
+import (
+	"bytes"
+	"strings"
+)
+
+// jsonIndent is the indentation of pretty-printed JSON bodies
+const jsonIndent = "    "
+
+// highlightJSON pretty-prints body like json.Indent, coloring each token
+// with paint and the theme. Tokens are copied as sent, so numbers keep
+// their precision. A body that is not valid JSON is formatted up to the
+// error and the rest kept as plain text when it was cut off or the error
+// follows a complete member or element, otherwise it is most likely text
+// such as "[INFO] started" and is returned unchanged, as are bodies that do
+// not start with an object or array.
+func highlightJSON(body []byte, theme Theme, paint func(code, s string) string) string {
+	h := jsonHighlighter{src: body, theme: theme, paint: paint}
+	h.skipSpace()
+	start := h.pos
+	if start == len(body) {
+		return string(body)
+	}
+
+	valid := h.value()
+	if valid {
+		h.skipSpace()
+		valid = h.pos == len(body)
+	}
+	if !valid {
+		container := body[start] == '{' || body[start] == '['
+		cutOff := h.truncated || h.pos == len(body)
+		if !container || !cutOff && h.complete == 0 {
+			return string(body)
+		}
+	}
+
+	// Whatever follows is copied as is, including the trailing whitespace
+	// json.Indent keeps. After an error, the whitespace separating it from
+	// the formatted part has already been written.
+	rest := body[h.end:]
+	if !valid && strings.HasSuffix(h.out.String(), " ") {
+		rest = bytes.TrimLeft(rest, " \t\r\n")
+	}
+	h.out.Write(rest)
+	return h.out.String()
+}
+
+// jsonHighlighter is a recursive descent tokenizer writing the formatted
+// document to out as it goes. Parsing stops at the first error, end is
+// the offset after the last token written, complete counts the members and
+// elements parsed and truncated is set when a token runs into the end of
+// the body.
+type jsonHighlighter struct {
+	src       []byte
+	pos       int
+	end       int
+	depth     int
+	complete  int
+	truncated bool
+	out       strings.Builder
+	theme     Theme
+	paint     func(code, s string) string
+}
+
+// value formats the value at the current position
+func (h *jsonHighlighter) value() bool {
+	if h.pos == len(h.src) {
+		return false
+	}
+	switch c := h.src[h.pos]; {
+	case c == '{':
+		return h.object()
+	case c == '[':
+		return h.array()
+	case c == '"':
+		return h.string(h.theme.JSONString)
+	case c == '-' || (c >= '0' && c <= '9'):
+		return h.number()
+	case c == 't':
+		return h.literal("true", h.theme.JSONBool)
+	case c == 'f':
+		return h.literal("false", h.theme.JSONBool)
+	case c == 'n':
+		return h.literal("null", h.theme.JSONNull)
+	default:
+		return false
+	}
+}
+
+// object formats an object, one member per line
+func (h *jsonHighlighter) object() bool {
+	h.punctuation("{")
+	h.skipSpace()
+	if h.peek('}') {
+		h.punctuation("}")
+		return true
+	}
+	h.depth++
+	for {
+		h.newline()
+		if !h.peek('"') {
+			return false
+		}
+		if !h.string(h.theme.JSONKey) {
+			return false
+		}
+		h.skipSpace()
+		if !h.peek(':') {
+			return false
+		}
+		h.punctuation(":")
+		h.out.WriteByte(' ')
+		h.skipSpace()
+		if !h.value() {
+			return false
+		}
+		h.complete++
+		if done, ok := h.next('}'); done || !ok {
+			return ok
+		}
+	}
+}
+
+// array formats an array, one element per line
+func (h *jsonHighlighter) array() bool {
+	h.punctuation("[")
+	h.skipSpace()
+	if h.peek(']') {
+		h.punctuation("]")
+		return true
+	}
+	h.depth++
+	for {
+		h.newline()
+		if !h.value() {
+			return false
+		}
+		h.complete++
+		if done, ok := h.next(']'); done || !ok {
+			return ok
+		}
+	}
+}
+
+// next consumes the comma before another member or element, or the closing
+// bracket, reporting whether the container ended
+func (h *jsonHighlighter) next(closing byte) (done, ok bool) {
+	h.skipSpace()
+	switch {
+	case h.peek(','):
+		h.punctuation(",")
+		h.skipSpace()
+		return false, true
+	case h.peek(closing):
+		h.depth--
+		h.newline()
+		h.punctuation(string(closing))
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// string copies a string token, escapes included
+func (h *jsonHighlighter) string(code string) bool {
+	start := h.pos
+	for i := start + 1; i < len(h.src); i++ {
+		switch c := h.src[i]; {
+		case c == '"':
+			h.pos, h.end = i+1, i+1
+			h.out.WriteString(h.paint(code, string(h.src[start:h.pos])))
+			return true
+		case c == '\\':
+			i++
+			if i < len(h.src) && h.src[i] == 'u' {
+				if i+4 >= len(h.src) || !isHex(h.src[i+1:i+5]) {
+					return false
+				}
+				i += 4
+			} else if i == len(h.src) || !strings.ContainsRune(`"\/bfnrt`, rune(h.src[i])) {
+				return false
+			}
+		case c < 0x20:
+			return false
+		}
+	}
+	h.truncated = true
+	return false
+}
+
+// number copies a number token without converting it
+func (h *jsonHighlighter) number() bool {
+	start := h.pos
+	i := start
+	if h.src[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(h.src) && h.src[i] == '0':
+		i++
+	case i < len(h.src) && h.src[i] >= '1' && h.src[i] <= '9':
+		i = skipDigits(h.src, i)
+	default:
+		h.truncated = i == len(h.src)
+		return false
+	}
+	if i < len(h.src) && h.src[i] == '.' {
+		if i = skipDigits(h.src, i+1); !isDigit(h.src[i-1]) {
+			h.truncated = i == len(h.src)
+			return false
+		}
+	}
+	if i < len(h.src) && (h.src[i] == 'e' || h.src[i] == 'E') {
+		i++
+		if i < len(h.src) && (h.src[i] == '+' || h.src[i] == '-') {
+			i++
+		}
+		if i = skipDigits(h.src, i); !isDigit(h.src[i-1]) {
+			h.truncated = i == len(h.src)
+			return false
+		}
+	}
+	h.pos, h.end = i, i
+	h.out.WriteString(h.paint(h.theme.JSONNumber, string(h.src[start:i])))
+	return true
+}
+
+// literal copies true, false or null
+func (h *jsonHighlighter) literal(word, code string) bool {
+	if !bytes.HasPrefix(h.src[h.pos:], []byte(word)) {
+		h.truncated = bytes.HasPrefix([]byte(word), h.src[h.pos:])
+		return false
+	}
+	h.pos += len(word)
+	h.end = h.pos
+	h.out.WriteString(h.paint(code, word))
+	return true
+}
+
+// punctuation writes a structural character and moves past it
+func (h *jsonHighlighter) punctuation(s string) {
+	h.pos++
+	h.end = h.pos
+	h.out.WriteString(h.paint(h.theme.JSONPunctuation, s))
+}
+
+// newline starts a line indented to the current depth
+func (h *jsonHighlighter) newline() {
+	h.out.WriteByte('\n')
+	h.out.WriteString(strings.Repeat(jsonIndent, h.depth))
+}
+
+// peek reports whether the current byte is c
+func (h *jsonHighlighter) peek(c byte) bool {
+	return h.pos < len(h.src) && h.src[h.pos] == c
+}
+
+func (h *jsonHighlighter) skipSpace() {
+	for h.pos < len(h.src) {
+		switch h.src[h.pos] {
+		case ' ', '\t', '\r', '\n':
+			h.pos++
+		default:
+			return
+		}
+	}
+}
+
+func skipDigits(b []byte, i int) int {
+	for i < len(b) && isDigit(b[i]) {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(b []byte) bool {
+	for _, c := range b {
+		if !isDigit(c) && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -1,8 +1,15 @@
 // Package printer provides interfaces and implementations for formatting output
 package printer
 
-// Printer defines the interface for formatting and printing debug output
+// Printer defines the interface for rendering request/response exchanges
 type Printer interface {
+	// PrintExchange renders a complete request/response exchange
+	PrintExchange(e Exchange)
+}
+
+// BoxPrinter defines the primitive calls used to draw boxes, tables and
+// bodies. Wrap an implementation with Adapt to use it as a Printer.
+type BoxPrinter interface {
 	// PrintBox prints text in a bordered box with specified color
 	PrintBox(header, content, color string)
 
@@ -12,3 +19,51 @@ type Printer interface {
 	// PrintBody prints formatted body content
 	PrintBody(body []byte, header string)
 }
+
+// Batcher is implemented by printers that can group several calls into one
+// write, so output from concurrent requests does not interleave
+type Batcher interface {
+	// Batch calls fn with a printer whose output is flushed atomically
+	Batch(fn func(BoxPrinter))
+}
+
+// Batch calls fn with p, grouping the output into one write when p
+// implements Batcher
+func Batch(p BoxPrinter, fn func(BoxPrinter)) {
+	if b, ok := p.(Batcher); ok {
+		b.Batch(fn)
+		return
+	}
+	fn(p)
+}
+
+// Adapt returns a Printer that renders exchanges through the primitive
+// calls of bp, laid out the same way as ConsolePrinter
+func Adapt(bp BoxPrinter) Printer {
+	if p, ok := bp.(Printer); ok {
+		return p
+	}
+	return boxAdapter{bp}
+}
+
+// boxAdapter lets a BoxPrinter satisfy Printer
+type boxAdapter struct {
+	BoxPrinter
+}
+
+// PrintExchange renders the exchange through the wrapped BoxPrinter
+func (a boxAdapter) PrintExchange(e Exchange) {
+	Batch(a.BoxPrinter, func(p BoxPrinter) {
+		renderExchange(p, e)
+	})
+}
+
+// PrintStreamEvent renders a streamed event through the wrapped BoxPrinter
+func (a boxAdapter) PrintStreamEvent(e StreamEvent) {
+	renderStreamEvent(a.BoxPrinter, e)
+}
+
+// PrintSummary renders the sampling summary through the wrapped BoxPrinter
+func (a boxAdapter) PrintSummary(s Summary) {
+	renderSummary(a.BoxPrinter, s)
+}
@@ -0,0 +1,321 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// JSONPrinter implements Printer by writing one JSON object per line,
+// suitable for log shippers. The zero value writes to stdout.
+type JSONPrinter struct {
+	out io.Writer
+	mu  *sync.Mutex
+}
+
+// NewJSONPrinter creates a new JSON lines printer that writes to w
+func NewJSONPrinter(w io.Writer) *JSONPrinter {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &JSONPrinter{out: w, mu: &sync.Mutex{}}
+}
+
+type jsonExchange struct {
+	Time       time.Time              `json:"time"`
+	Method     string                 `json:"method,omitempty"`
+	URL        string                 `json:"url,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Duration   string                 `json:"duration"`
+	DurationMS float64                `json:"duration_ms"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty"`
+	SpanID     string                 `json:"span_id,omitempty"`
+	TraceURL   string                 `json:"trace_url,omitempty"`
+	Context    map[string]interface{} `json:"context,omitempty"`
+	Request    *jsonRequest           `json:"request,omitempty"`
+	Response   *jsonResponse          `json:"response,omitempty"`
+	Panic      *jsonPanic             `json:"panic,omitempty"`
+	Outbound   bool                   `json:"outbound,omitempty"`
+	Error      *jsonError             `json:"error,omitempty"`
+	Timing     *jsonTiming            `json:"timing,omitempty"`
+}
+
+type jsonRequest struct {
+	Headers       map[string]interface{} `json:"headers,omitempty"`
+	Query         map[string]interface{} `json:"query,omitempty"`
+	Body          json.RawMessage        `json:"body,omitempty"`
+	BodySize      int64                  `json:"body_size,omitempty"`
+	BodyTruncated bool                   `json:"body_truncated,omitempty"`
+	BodyRead      string                 `json:"body_read,omitempty"`
+}
+
+type jsonResponse struct {
+	Upgraded      bool                   `json:"upgraded,omitempty"`
+	Headers       map[string]interface{} `json:"headers,omitempty"`
+	Body          json.RawMessage        `json:"body,omitempty"`
+	BodySize      int64                  `json:"body_size,omitempty"`
+	BodyTruncated bool                   `json:"body_truncated,omitempty"`
+	Stream        *jsonStream            `json:"stream,omitempty"`
+}
+
+type jsonStream struct {
+	SSE    bool  `json:"sse"`
+	Events int   `json:"events"`
+	Bytes  int64 `json:"bytes"`
+}
+
+type jsonStreamEvent struct {
+	Time      time.Time       `json:"time"`
+	Kind      string          `json:"kind"`
+	Method    string          `json:"method"`
+	URL       string          `json:"url"`
+	Seq       int             `json:"seq"`
+	OffsetMS  float64         `json:"offset_ms"`
+	Event     string          `json:"event,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Retry     string          `json:"retry,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Size      int64           `json:"size,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+type jsonSummary struct {
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"`
+	Since      time.Time `json:"since"`
+	DurationMS float64   `json:"duration_ms"`
+	Logged     int       `json:"logged"`
+	Suppressed int       `json:"suppressed"`
+}
+
+type jsonTiming struct {
+	Phases            []jsonTimingPhase `json:"phases,omitempty"`
+	TimeToFirstByteMS float64           `json:"ttfb_ms,omitempty"`
+	TotalMS           float64           `json:"total_ms"`
+	ConnReused        bool              `json:"conn_reused,omitempty"`
+}
+
+type jsonTimingPhase struct {
+	Name       string  `json:"name"`
+	StartMS    float64 `json:"start_ms"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+type jsonError struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+type jsonPanic struct {
+	Error string `json:"error"`
+	Stack string `json:"stack,omitempty"`
+}
+
+// PrintExchange writes the exchange as a single JSON object
+func (p *JSONPrinter) PrintExchange(e Exchange) {
+	out := jsonExchange{
+		Time:       e.Time,
+		Duration:   e.Duration.String(),
+		DurationMS: milliseconds(e.Duration),
+		Outbound:   e.Outbound,
+		RequestID:  e.RequestID,
+		TraceID:    e.TraceID,
+		SpanID:     e.SpanID,
+		TraceURL:   e.TraceURL,
+	}
+
+	if len(e.Attrs) > 0 {
+		out.Context = attrValues(e.Attrs)
+		for key, value := range out.Context {
+			// Channels, funcs and cycles would fail the whole line
+			if _, err := json.Marshal(value); err != nil {
+				out.Context[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	if req := e.Request; req != nil {
+		out.Method = req.Method
+		out.URL = req.URL
+		out.Request = &jsonRequest{
+			Headers:       flattenValues(req.Headers),
+			Query:         flattenValues(req.Query),
+			Body:          jsonBody(req.Body),
+			BodySize:      req.BodySize,
+			BodyTruncated: req.BodyTruncated,
+		}
+		if req.BodyRead != BodyReadUnknown {
+			out.Request.BodyRead = req.BodyRead.String()
+		}
+	}
+
+	if resp := e.Response; resp != nil {
+		out.Status = resp.StatusCode
+		out.Response = &jsonResponse{
+			Upgraded:      resp.Upgraded,
+			Headers:       flattenValues(resp.Headers),
+			Body:          jsonBody(resp.Body),
+			BodySize:      resp.BodySize,
+			BodyTruncated: resp.BodyTruncated,
+		}
+		if resp.Stream != nil {
+			out.Response.Stream = &jsonStream{
+				SSE:    resp.Stream.SSE,
+				Events: resp.Stream.Events,
+				Bytes:  resp.Stream.Bytes,
+			}
+		}
+	}
+
+	if e.Panic != nil {
+		out.Panic = &jsonPanic{
+			Error: fmt.Sprint(e.Panic.Value),
+			Stack: string(e.Panic.Stack),
+		}
+	}
+
+	if e.Error != nil {
+		out.Error = &jsonError{Kind: e.Error.Kind, Message: e.Error.Err.Error()}
+	}
+
+	if t := e.Timing; t != nil {
+		out.Timing = &jsonTiming{
+			TimeToFirstByteMS: milliseconds(t.TimeToFirstByte),
+			TotalMS:           milliseconds(t.Total),
+			ConnReused:        t.ConnReused,
+		}
+		for _, phase := range t.Phases {
+			out.Timing.Phases = append(out.Timing.Phases, jsonTimingPhase{
+				Name:       phase.Name,
+				StartMS:    milliseconds(phase.Start),
+				DurationMS: milliseconds(phase.Duration),
+			})
+		}
+	}
+
+	p.encode(out)
+}
+
+// PrintStreamEvent writes a streamed event or chunk as its own JSON object
+func (p *JSONPrinter) PrintStreamEvent(e StreamEvent) {
+	out := jsonStreamEvent{
+		Time:      time.Now(),
+		Kind:      "chunk",
+		Method:    e.Method,
+		URL:       e.URL,
+		Seq:       e.Seq,
+		OffsetMS:  milliseconds(e.Offset),
+		Data:      jsonBody(e.Chunk),
+		Size:      e.Size,
+		Truncated: e.Truncated,
+	}
+	switch {
+	case e.Start:
+		out.Kind = "start"
+	case e.SSE:
+		out.Kind = "event"
+		out.Event = e.Event
+		out.ID = e.ID
+		out.Retry = e.Retry
+		out.Data = jsonBody([]byte(e.Data))
+	}
+	p.encode(out)
+}
+
+// PrintSummary writes the sampling summary as its own JSON object
+func (p *JSONPrinter) PrintSummary(s Summary) {
+	p.encode(jsonSummary{
+		Time:       time.Now(),
+		Kind:       "summary",
+		Since:      s.Since,
+		DurationMS: milliseconds(s.Duration),
+		Logged:     s.Logged,
+		Suppressed: s.Suppressed,
+	})
+}
+
+// encode marshals v and writes it as one line while holding the lock
+func (p *JSONPrinter) encode(v interface{}) {
+	var line bytes.Buffer
+	enc := json.NewEncoder(&line)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		line.Reset()
+		enc.Encode(map[string]string{"error": err.Error()})
+	}
+
+	out, mu := p.out, p.mu
+	if out == nil {
+		out = os.Stdout
+	}
+	if mu == nil {
+		mu = &defaultMu
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	out.Write(line.Bytes())
+}
+
+// milliseconds converts d to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// jsonBody embeds JSON bodies as raw JSON and everything else as a string
+func jsonBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err == nil {
+		return compact.Bytes()
+	}
+	encoded, _ := json.Marshal(string(body))
+	return encoded
+}
+
+// flattenValues converts multi-value maps such as headers and query
+// parameters, unwrapping keys that only have a single value
+func flattenValues(values map[string][]string) map[string]interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make(map[string]interface{}, len(values))
+	for key, vals := range values {
+		if len(vals) == 1 {
+			result[key] = vals[0]
+		} else {
+			result[key] = vals
+		}
+	}
+	return result
+}
+
+// attrValues converts context attributes to a map, resolving LogValuers
+// and flattening groups into dotted keys
+func attrValues(attrs []slog.Attr) map[string]interface{} {
+	values := make(map[string]interface{}, len(attrs))
+	var add func(prefix string, attrs []slog.Attr)
+	add = func(prefix string, attrs []slog.Attr) {
+		for _, attr := range attrs {
+			value := attr.Value.Resolve()
+			key := prefix + attr.Key
+			if value.Kind() == slog.KindGroup {
+				if attr.Key != "" {
+					key += "."
+				}
+				add(key, value.Group())
+				continue
+			}
+			values[key] = value.Any()
+		}
+	}
+	add("", attrs)
+	return values
+}
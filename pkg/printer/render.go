@@ -0,0 +1,298 @@
+package printer
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// renderExchange lays out an exchange through the primitive box calls
+func renderExchange(p BoxPrinter, e Exchange) {
+	if e.Panic != nil {
+		renderPanic(p, e.Panic)
+	}
+	if e.Request != nil {
+		renderRequest(p, e)
+	}
+	if e.Response != nil {
+		renderResponse(p, e)
+	}
+	if e.Error != nil {
+		renderError(p, e)
+	}
+	if e.Outbound && e.Timing != nil && len(e.Timing.Phases) > 0 {
+		renderTiming(p, e)
+	}
+}
+
+// renderRequest prints the request box followed by its tables and body
+func renderRequest(p BoxPrinter, e Exchange) {
+	req := e.Request
+
+	// Print request header
+	header := fmt.Sprintf("Request - %s", highlightMethod(p, req.Method))
+	if e.Outbound {
+		header = "Outgoing " + header
+	}
+	content := strings.Join(append([]string{req.URL}, correlation(e)...), "\n")
+	if req.BodyRead != BodyReadUnknown {
+		content += fmt.Sprintf("\nBody: %s (%d bytes)", req.BodyRead, req.BodySize)
+	}
+	p.PrintBox(header, content, "blue")
+
+	// Print context attributes if any
+	if len(e.Attrs) > 0 {
+		p.PrintTable(attrValues(e.Attrs), "Context Attributes")
+	}
+
+	// Print query parameters
+	if len(req.Query) > 0 {
+		p.PrintTable(flattenValues(req.Query), "Query Parameters")
+	}
+
+	// Print headers
+	if len(req.Headers) > 0 {
+		p.PrintTable(flattenValues(req.Headers), "Headers")
+	}
+
+	// Print body
+	if len(req.Body) > 0 {
+		renderBody(p, req.Body, req.BodySize, req.BodyTruncated, "Request Body")
+	}
+}
+
+// renderResponse prints the response box followed by its headers and body
+func renderResponse(p BoxPrinter, e Exchange) {
+	resp := e.Response
+
+	if resp.Upgraded {
+		header := fmt.Sprintf("🔌 Response - Connection upgraded - Time: %s", e.Duration)
+		p.PrintBox(header, strings.Join(correlation(e), "\n"), "cyan")
+		if len(resp.Headers) > 0 {
+			p.PrintTable(flattenValues(resp.Headers), "Response Headers")
+		}
+		return
+	}
+
+	statusEmoji := resp.Emoji
+	statusColor := "green"
+	if resp.StatusCode >= 400 {
+		statusColor = "red"
+	}
+	if statusEmoji == "" {
+		if resp.StatusCode >= 400 {
+			statusEmoji = "❌"
+		} else {
+			statusEmoji = "✅"
+		}
+	}
+
+	status := highlightStatus(p, resp.StatusCode, fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)))
+	header := fmt.Sprintf("%s Response - Status: %s - Time: %s", statusEmoji, status, e.Duration)
+	content := strings.Join(correlation(e), "\n")
+	if resp.Stream != nil {
+		kind, unit := "Stream", "chunks"
+		if resp.Stream.SSE {
+			kind, unit = "Event stream", "events"
+		}
+		stream := fmt.Sprintf("📡 %s ended - %d %s, %d bytes", kind, resp.Stream.Events, unit, resp.Stream.Bytes)
+		content = strings.TrimPrefix(content+"\n"+stream, "\n")
+	}
+	if !e.Outbound && e.Timing != nil {
+		if summary := timingSummary(e.Timing); summary != "" {
+			content = strings.TrimPrefix(content+"\n"+summary, "\n")
+		}
+	}
+	p.PrintBox(header, content, statusColor)
+
+	// Print response headers
+	if len(resp.Headers) > 0 {
+		p.PrintTable(flattenValues(resp.Headers), "Response Headers")
+	}
+
+	// Print response body
+	if len(resp.Body) > 0 {
+		renderBody(p, resp.Body, resp.BodySize, resp.BodyTruncated, "Response Body")
+	}
+}
+
+// highlighter is implemented by printers that color the method and status
+// in box headers
+type highlighter interface {
+	highlightMethod(method string) string
+	highlightStatus(code int, status string) string
+}
+
+// highlightMethod returns method as the printer highlights it
+func highlightMethod(p BoxPrinter, method string) string {
+	if h, ok := p.(highlighter); ok {
+		return h.highlightMethod(method)
+	}
+	return method
+}
+
+// highlightStatus returns status as the printer highlights it
+func highlightStatus(p BoxPrinter, code int, status string) string {
+	if h, ok := p.(highlighter); ok {
+		return h.highlightStatus(code, status)
+	}
+	return status
+}
+
+// correlation returns the lines tying an exchange to other logs and traces
+func correlation(e Exchange) []string {
+	var lines []string
+	if e.RequestID != "" {
+		lines = append(lines, "Request ID: "+e.RequestID)
+	}
+	if e.TraceID != "" {
+		lines = append(lines, fmt.Sprintf("Trace ID: %s · Span ID: %s", e.TraceID, e.SpanID))
+	}
+	if e.TraceURL != "" {
+		lines = append(lines, "🔗 "+e.TraceURL)
+	}
+	return lines
+}
+
+// renderStreamEvent prints a single streamed event or chunk in a box, or the
+// request header box when the stream starts
+func renderStreamEvent(p BoxPrinter, e StreamEvent) {
+	if e.Start {
+		header := fmt.Sprintf("Request - %s", highlightMethod(p, e.Method))
+		p.PrintBox(header, e.URL, "blue")
+		return
+	}
+	if e.SSE {
+		header := fmt.Sprintf("📡 Event #%d +%s - %s %s", e.Seq, e.Offset, e.Method, e.URL)
+		var lines []string
+		if e.Event != "" {
+			lines = append(lines, "event: "+e.Event)
+		}
+		if e.ID != "" {
+			lines = append(lines, "id: "+e.ID)
+		}
+		if e.Retry != "" {
+			lines = append(lines, "retry: "+e.Retry)
+		}
+		for _, line := range strings.Split(e.Data, "\n") {
+			lines = append(lines, "data: "+line)
+		}
+		if e.Truncated {
+			lines = append(lines, fmt.Sprintf("… truncated, %d bytes total", e.Size))
+		}
+		p.PrintBox(header, strings.Join(lines, "\n"), "cyan")
+		return
+	}
+
+	size := max(e.Size, int64(len(e.Chunk)))
+	header := fmt.Sprintf("📦 Chunk #%d +%s (%d bytes) - %s %s", e.Seq, e.Offset, size, e.Method, e.URL)
+	content := strings.TrimRight(string(e.Chunk), "\n")
+	if e.Truncated {
+		content += fmt.Sprintf("\n… truncated, %d bytes total", size)
+	}
+	p.PrintBox(header, content, "cyan")
+}
+
+// renderSummary prints the number of exchanges suppressed during an interval
+func renderSummary(p BoxPrinter, s Summary) {
+	header := fmt.Sprintf("📊 Sampling summary - last %s", s.Duration.Round(time.Millisecond))
+	content := fmt.Sprintf("%d exchanges suppressed, %d logged", s.Suppressed, s.Logged)
+	p.PrintBox(header, content, "yellow")
+}
+
+// footerBodyPrinter is implemented by printers that can show a footer below
+// a body, such as the truncation marker
+type footerBodyPrinter interface {
+	printBody(body []byte, header, footer string)
+}
+
+// renderBody prints a body, noting when only a prefix was captured
+func renderBody(p BoxPrinter, body []byte, size int64, truncated bool, header string) {
+	footer := ""
+	if truncated {
+		footer = fmt.Sprintf("… truncated, %d bytes total", size)
+	}
+	if fp, ok := p.(footerBodyPrinter); ok {
+		fp.printBody(body, header, footer)
+		return
+	}
+	if footer != "" {
+		body = append(body[:len(body):len(body)], "\n"+footer...)
+	}
+	p.PrintBody(body, header)
+}
+
+// renderError prints a failed outbound request in a red box
+func renderError(p BoxPrinter, e Exchange) {
+	header := fmt.Sprintf("❌ %s - Time: %s", e.Error.Kind, e.Duration)
+	p.PrintBox(header, e.Error.Err.Error(), "red")
+}
+
+// timingBarWidth is the width of the waterfall bars in cells
+const timingBarWidth = 30
+
+// renderTiming prints the timing phases as a waterfall bar chart
+func renderTiming(p BoxPrinter, e Exchange) {
+	t := e.Timing
+	header := "⏱ Timing"
+	if e.Outbound {
+		if t.ConnReused {
+			header += " (connection reused)"
+		} else {
+			header += " (new connection)"
+		}
+	}
+
+	nameWidth := len("Time to first byte")
+	for _, phase := range t.Phases {
+		nameWidth = max(nameWidth, len(phase.Name))
+	}
+
+	total := t.Total
+	if total <= 0 {
+		total = 1
+	}
+
+	var lines []string
+	for _, phase := range t.Phases {
+		offset := int(int64(timingBarWidth) * int64(phase.Start) / int64(total))
+		width := int(int64(timingBarWidth) * int64(phase.Duration) / int64(total))
+		offset = min(offset, timingBarWidth-1)
+		width = min(max(width, 1), timingBarWidth-offset)
+		bar := strings.Repeat(" ", offset) + strings.Repeat("█", width) + strings.Repeat(" ", timingBarWidth-offset-width)
+		lines = append(lines, fmt.Sprintf("%-*s %10s |%s|", nameWidth, phase.Name, phase.Duration.Round(time.Microsecond), bar))
+	}
+	if t.TimeToFirstByte > 0 {
+		lines = append(lines, fmt.Sprintf("%-*s %10s", nameWidth, "Time to first byte", t.TimeToFirstByte.Round(time.Microsecond)))
+	}
+	p.PrintBox(header, strings.Join(lines, "\n"), "yellow")
+}
+
+// timingSummary lists the timing phases on a single line, with the time to
+// first byte after the first phase
+func timingSummary(t *Timing) string {
+	var parts []string
+	for i, phase := range t.Phases {
+		parts = append(parts, fmt.Sprintf("%s: %s", phase.Name, phase.Duration.Round(time.Microsecond)))
+		if i == 0 && t.TimeToFirstByte > 0 {
+			parts = append(parts, fmt.Sprintf("TTFB: %s", t.TimeToFirstByte.Round(time.Microsecond)))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderPanic prints panic details in a box
+func renderPanic(p BoxPrinter, pnc *Panic) {
+	errorMsg := fmt.Sprintf("💥 PANIC RECOVERED 💥\n\nError: %v\n", pnc.Value)
+	// Just a portion of the stack to avoid huge logs
+	lines := strings.Split(string(pnc.Stack), "\n")
+	if len(lines) > 15 {
+		lines = lines[:15]
+		lines = append(lines, "...")
+	}
+	stackMsg := fmt.Sprintf("Stack Trace (truncated):\n%s", strings.Join(lines, "\n"))
+	content := errorMsg + "\n" + stackMsg
+
+	p.PrintBox("PANIC", content, "red")
+}
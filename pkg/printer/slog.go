@@ -0,0 +1,219 @@
+package printer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SlogPrinter implements Printer by emitting each exchange as a slog
+// record, so it flows through whatever handler the application configured.
+// The level follows the outcome: Info for 1xx to 3xx, Warn for 4xx and
+// Error for 5xx, panics and transport errors.
+type SlogPrinter struct {
+	logger *slog.Logger
+}
+
+// NewSlogPrinter creates a printer that logs to logger, slog.Default()
+// when nil
+func NewSlogPrinter(logger *slog.Logger) *SlogPrinter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogPrinter{logger: logger}
+}
+
+// PrintExchange logs the exchange with grouped request and response attrs
+func (p *SlogPrinter) PrintExchange(e Exchange) {
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := exchangeLevel(e)
+	if !p.logger.Enabled(ctx, level) {
+		return
+	}
+
+	msg := "HTTP request"
+	if e.Outbound {
+		msg = "HTTP outgoing request"
+	}
+
+	attrs := []slog.Attr{slog.Duration("duration", e.Duration)}
+	if e.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", e.RequestID))
+	}
+	if e.TraceID != "" {
+		attrs = append(attrs, slog.String("trace_id", e.TraceID), slog.String("span_id", e.SpanID))
+	}
+	if e.TraceURL != "" {
+		attrs = append(attrs, slog.String("trace_url", e.TraceURL))
+	}
+	if len(e.Attrs) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "context", Value: slog.GroupValue(e.Attrs...)})
+	}
+	if req := e.Request; req != nil {
+		attrs = append(attrs, slog.Attr{Key: "request", Value: slog.GroupValue(requestAttrs(req)...)})
+	}
+	if resp := e.Response; resp != nil {
+		attrs = append(attrs, slog.Attr{Key: "response", Value: slog.GroupValue(responseAttrs(resp)...)})
+	}
+	if e.Panic != nil {
+		attrs = append(attrs, slog.Group("panic",
+			slog.String("error", fmt.Sprint(e.Panic.Value)),
+			slog.String("stack", string(e.Panic.Stack))))
+	}
+	if e.Error != nil {
+		attrs = append(attrs, slog.Group("error",
+			slog.String("kind", e.Error.Kind),
+			slog.String("message", e.Error.Err.Error())))
+	}
+	if t := e.Timing; t != nil {
+		attrs = append(attrs, slog.Attr{Key: "timing", Value: slog.GroupValue(timingAttrs(t)...)})
+	}
+
+	p.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// PrintStreamEvent logs a streamed event or chunk at debug level
+func (p *SlogPrinter) PrintStreamEvent(e StreamEvent) {
+	attrs := []slog.Attr{
+		slog.String("method", e.Method),
+		slog.String("url", e.URL),
+		slog.Int("seq", e.Seq),
+		slog.Duration("offset", e.Offset),
+	}
+	if e.Start {
+		p.logger.LogAttrs(context.Background(), slog.LevelDebug, "HTTP stream started", attrs...)
+		return
+	}
+	if !e.SSE {
+		attrs = append(attrs, slog.String("chunk", string(e.Chunk)), slog.Int64("size", max(e.Size, int64(len(e.Chunk)))))
+		if e.Truncated {
+			attrs = append(attrs, slog.Bool("truncated", true))
+		}
+		p.logger.LogAttrs(context.Background(), slog.LevelDebug, "HTTP stream chunk", attrs...)
+		return
+	}
+	for _, field := range []struct{ key, value string }{{"event", e.Event}, {"id", e.ID}, {"retry", e.Retry}} {
+		if field.value != "" {
+			attrs = append(attrs, slog.String(field.key, field.value))
+		}
+	}
+	attrs = append(attrs, slog.String("data", e.Data))
+	if e.Truncated {
+		attrs = append(attrs, slog.Int64("size", e.Size), slog.Bool("truncated", true))
+	}
+	p.logger.LogAttrs(context.Background(), slog.LevelDebug, "HTTP stream event", attrs...)
+}
+
+// PrintSummary logs the sampling summary at info level
+func (p *SlogPrinter) PrintSummary(s Summary) {
+	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Exchanges suppressed by sampling",
+		slog.Int("suppressed", s.Suppressed),
+		slog.Int("logged", s.Logged),
+		slog.Duration("interval", s.Duration))
+}
+
+// exchangeLevel derives the record level from the outcome of an exchange
+func exchangeLevel(e Exchange) slog.Level {
+	switch {
+	case e.Panic != nil, e.Error != nil:
+		return slog.LevelError
+	case e.Response == nil:
+		return slog.LevelInfo
+	case e.Response.StatusCode >= 500:
+		return slog.LevelError
+	case e.Response.StatusCode >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func requestAttrs(req *Request) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL),
+	}
+	if len(req.Headers) > 0 {
+		attrs = append(attrs, valuesAttr("headers", req.Headers))
+	}
+	if len(req.Query) > 0 {
+		attrs = append(attrs, valuesAttr("query", req.Query))
+	}
+	attrs = append(attrs, bodyAttrs(req.Body, req.BodySize, req.BodyTruncated)...)
+	if req.BodyRead != BodyReadUnknown {
+		attrs = append(attrs, slog.String("body_read", req.BodyRead.String()))
+	}
+	return attrs
+}
+
+func responseAttrs(resp *Response) []slog.Attr {
+	attrs := []slog.Attr{slog.Int("status", resp.StatusCode)}
+	if resp.Upgraded {
+		attrs = append(attrs, slog.Bool("upgraded", true))
+	} else {
+		attrs = append(attrs, slog.String("status_text", http.StatusText(resp.StatusCode)))
+	}
+	if len(resp.Headers) > 0 {
+		attrs = append(attrs, valuesAttr("headers", resp.Headers))
+	}
+	attrs = append(attrs, bodyAttrs(resp.Body, resp.BodySize, resp.BodyTruncated)...)
+	if s := resp.Stream; s != nil {
+		attrs = append(attrs, slog.Group("stream",
+			slog.Bool("sse", s.SSE),
+			slog.Int("events", s.Events),
+			slog.Int64("bytes", s.Bytes)))
+	}
+	return attrs
+}
+
+func bodyAttrs(body []byte, size int64, truncated bool) []slog.Attr {
+	if len(body) == 0 {
+		return nil
+	}
+	attrs := []slog.Attr{
+		slog.String("body", string(body)),
+		slog.Int64("body_size", size),
+	}
+	if truncated {
+		attrs = append(attrs, slog.Bool("body_truncated", true))
+	}
+	return attrs
+}
+
+func timingAttrs(t *Timing) []slog.Attr {
+	attrs := []slog.Attr{slog.Duration("total", t.Total)}
+	if t.TimeToFirstByte > 0 {
+		attrs = append(attrs, slog.Duration("ttfb", t.TimeToFirstByte))
+	}
+	for _, phase := range t.Phases {
+		key := strings.ReplaceAll(strings.ToLower(phase.Name), " ", "_")
+		attrs = append(attrs, slog.Duration(key, phase.Duration))
+	}
+	if t.ConnReused {
+		attrs = append(attrs, slog.Bool("conn_reused", true))
+	}
+	return attrs
+}
+
+// valuesAttr groups headers or query parameters sorted by name, unwrapping
+// keys that only have a single value
+func valuesAttr(key string, values map[string][]string) slog.Attr {
+	flat := flattenValues(values)
+	names := make([]string, 0, len(flat))
+	for name := range flat {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, slog.Any(name, flat[name]))
+	}
+	return slog.Attr{Key: key, Value: slog.GroupValue(attrs...)}
+}
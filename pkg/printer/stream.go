@@ -0,0 +1,51 @@
+package printer
+
+import "time"
+
+// StreamEvent is one Server-Sent Event or flushed chunk of a streamed
+// response, printed as soon as the handler writes it
+type StreamEvent struct {
+	// Method and URL identify the exchange the event belongs to
+	Method string
+	URL    string
+
+	// Seq numbers the events of one response starting at 1
+	Seq int
+
+	// Offset is the time since the request started
+	Offset time.Duration
+
+	// Start marks the event sent once the response turns out to be a
+	// stream, before any of its chunks or events, so the request can be
+	// shown ahead of them. Only Method, URL, Offset and SSE are set.
+	Start bool
+
+	// SSE reports whether the event was parsed from a text/event-stream,
+	// in which case the Event, ID, Data and Retry fields are set
+	SSE   bool
+	Event string
+	ID    string
+	Data  string
+	Retry string
+
+	// Chunk holds the raw bytes written between flushes of other streams.
+	// Size counts the bytes of the chunk or event as written and Truncated
+	// reports that Chunk or the event fields only hold a prefix because of
+	// the body size limit.
+	Chunk     []byte
+	Size      int64
+	Truncated bool
+}
+
+// StreamSummary describes a streamed response once the stream has ended
+type StreamSummary struct {
+	SSE    bool
+	Events int
+	Bytes  int64
+}
+
+// StreamPrinter is implemented by printers that can show streamed response
+// events incrementally
+type StreamPrinter interface {
+	PrintStreamEvent(e StreamEvent)
+}
@@ -0,0 +1,181 @@
+package printer
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/1saifj/reqpretty/internal/styles"
+)
+
+// ColorMode controls when ConsolePrinter emits ANSI colors
+type ColorMode int
+
+const (
+	// ColorAuto colors output written to a terminal. NO_COLOR turns colors
+	// off and FORCE_COLOR turns them on regardless of the destination.
+	ColorAuto ColorMode = iota
+	// ColorAlways always emits colors
+	ColorAlways
+	// ColorNever never emits colors
+	ColorNever
+)
+
+// BoxStyle holds the glyphs boxes and tables are drawn with
+type BoxStyle struct {
+	TopLeft     string
+	TopRight    string
+	BottomLeft  string
+	BottomRight string
+	Horizontal  string
+	Vertical    string
+	TeeDown     string
+	TeeUp       string
+
+	// Continuation starts lines wrapped to fit the width
+	Continuation string
+
+	// ASCII also replaces the symbols and emojis the printer adds to
+	// headers with plain text, for environments without Unicode
+	ASCII bool
+}
+
+var (
+	// UnicodeBox draws boxes with Unicode box-drawing characters
+	UnicodeBox = BoxStyle{
+		TopLeft:      TopLeft,
+		TopRight:     TopRight,
+		BottomLeft:   BottomLeft,
+		BottomRight:  BottomRight,
+		Horizontal:   Horizontal,
+		Vertical:     Vertical,
+		TeeDown:      TeeDown,
+		TeeUp:        TeeUp,
+		Continuation: "↪ ",
+	}
+
+	// ASCIIBox draws boxes with +, - and |
+	ASCIIBox = BoxStyle{
+		TopLeft:      "+",
+		TopRight:     "+",
+		BottomLeft:   "+",
+		BottomRight:  "+",
+		Horizontal:   "-",
+		Vertical:     "|",
+		TeeDown:      "+",
+		TeeUp:        "+",
+		Continuation: "> ",
+		ASCII:        true,
+	}
+)
+
+// asciiSymbols replaces the symbols used by the renderer in ASCII mode
+var asciiSymbols = strings.NewReplacer(
+	"✅", "[OK]",
+	"❌", "[ERROR]",
+	"💥", "!!",
+	"🔌", "[UPGRADE]",
+	"📡", "[STREAM]",
+	"📦", "[CHUNK]",
+	"📊", "[SUMMARY]",
+	"🔗", "[LINK]",
+	"⏱", "[TIMING]",
+	"█", "#",
+	"…", "...",
+	"·", "|",
+	"µs", "us",
+)
+
+// colorEnabled resolves mode for output written to w
+func colorEnabled(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" && force != "0" && force != "false" {
+		return true
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a character device such as a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// glyphs returns the box style, Unicode for the zero value
+func (p *ConsolePrinter) glyphs() BoxStyle {
+	if p.box == (BoxStyle{}) {
+		return UnicodeBox
+	}
+	return p.box
+}
+
+// ansi returns code, or nothing when colors are disabled
+func (p *ConsolePrinter) ansi(code string) string {
+	if p.noColor {
+		return ""
+	}
+	return code
+}
+
+// text prepares printer-supplied text for the box style
+func (p *ConsolePrinter) text(s string) string {
+	if p.box.ASCII {
+		return asciiSymbols.Replace(s)
+	}
+	return s
+}
+
+// MinWidth is the narrowest output ConsolePrinter lays out, a table row
+// with a single cell for the key and for the value. Narrower widths are
+// raised to it.
+const MinWidth = 9
+
+// width returns the maximum output width, zero when unlimited
+func (p *ConsolePrinter) width() int {
+	if p.maxWidth > 0 {
+		return max(p.maxWidth, MinWidth)
+	}
+	if f, ok := p.out.(*os.File); ok && isTerminal(f) {
+		if cols, ok := terminalWidth(f); ok {
+			return max(cols, MinWidth)
+		}
+	}
+	return 0
+}
+
+// wrapLines soft-wraps lines to fit inside a box, whose borders and
+// padding take six cells
+func (p *ConsolePrinter) wrapLines(lines []string) []string {
+	limit := p.width()
+	if limit <= 0 {
+		return lines
+	}
+	var wrapped []string
+	for _, line := range lines {
+		wrapped = append(wrapped, styles.Wrap(line, max(limit-6, 1), p.glyphs().Continuation)...)
+	}
+	return wrapped
+}
+
+// title prepares a section title, truncated to fit the width
+func (p *ConsolePrinter) title(s string) string {
+	// The title is followed by two spaces
+	return styles.Truncate(p.text(s), p.width()-2, p.ellipsis())
+}
+
+// ellipsis marks truncated text
+func (p *ConsolePrinter) ellipsis() string {
+	return p.text("…")
+}
@@ -0,0 +1,18 @@
+package printer
+
+import "time"
+
+// Summary reports how many exchanges sampling and rate limiting held back
+// during one interval
+type Summary struct {
+	Since      time.Time
+	Duration   time.Duration
+	Logged     int
+	Suppressed int
+}
+
+// SummaryPrinter is implemented by printers that can show the periodic
+// sampling summary
+type SummaryPrinter interface {
+	PrintSummary(s Summary)
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package printer
+
+import "os"
+
+// terminalWidth reports that the terminal size is unknown on this platform
+func terminalWidth(f *os.File) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,191 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/1saifj/reqpretty/internal/styles"
+)
+
+// Theme holds the ANSI sequences ConsolePrinter colors each part of the
+// output with. Sequences can be combined, e.g. Bold + BrightCyan, and an
+// empty field leaves that part uncolored.
+type Theme struct {
+	// Box borders by purpose: requests, successful responses, failures,
+	// warnings such as timing and sampling summaries, informational boxes
+	// such as streamed events, and boxes printed with any other color name
+	Request string
+	Success string
+	Failure string
+	Warning string
+	Info    string
+	Default string
+
+	// Method and status in box headers, the status by class
+	Method            string
+	StatusSuccess     string
+	StatusRedirect    string
+	StatusClientError string
+	StatusServerError string
+
+	// Tables: borders, titles, keys and values
+	TableBorder string
+	TableTitle  string
+	Key         string
+	Value       string
+
+	// Bodies: borders and titles
+	BodyBorder string
+	BodyTitle  string
+
+	// JSON tokens in bodies
+	JSONKey         string
+	JSONString      string
+	JSONNumber      string
+	JSONBool        string
+	JSONNull        string
+	JSONPunctuation string
+}
+
+var (
+	// DarkTheme uses bright colors that stand out on dark backgrounds
+	DarkTheme = Theme{
+		Request:           BrightBlue,
+		Success:           BrightGreen,
+		Failure:           BrightRed,
+		Warning:           BrightYellow,
+		Info:              BrightCyan,
+		Default:           White,
+		Method:            Bold + BrightMagenta,
+		StatusSuccess:     BrightGreen,
+		StatusRedirect:    BrightCyan,
+		StatusClientError: BrightYellow,
+		StatusServerError: BrightRed,
+		TableBorder:       BrightCyan,
+		TableTitle:        Bold + BrightCyan,
+		Key:               Cyan,
+		BodyBorder:        BrightYellow,
+		BodyTitle:         Bold + BrightYellow,
+		JSONKey:           BrightBlue,
+		JSONString:        Green,
+		JSONNumber:        BrightMagenta,
+		JSONBool:          Yellow,
+		JSONNull:          BrightBlack,
+	}
+
+	// LightTheme uses darker colors that stay readable on light backgrounds
+	LightTheme = Theme{
+		Request:           Blue,
+		Success:           Green,
+		Failure:           Red,
+		Warning:           Magenta,
+		Info:              Blue,
+		Default:           Blue,
+		Method:            Bold + Magenta,
+		StatusSuccess:     Green,
+		StatusRedirect:    Blue,
+		StatusClientError: Magenta,
+		StatusServerError: Red,
+		TableBorder:       Blue,
+		TableTitle:        Bold + Blue,
+		Key:               Blue,
+		BodyBorder:        Magenta,
+		BodyTitle:         Bold + Magenta,
+		JSONKey:           Blue,
+		JSONString:        Green,
+		JSONNumber:        Magenta,
+		JSONBool:          Red,
+		JSONNull:          Faint,
+	}
+
+	// HighContrastTheme draws everything bold in bright colors
+	HighContrastTheme = Theme{
+		Request:           Bold + BrightBlue,
+		Success:           Bold + BrightGreen,
+		Failure:           Bold + BrightRed,
+		Warning:           Bold + BrightYellow,
+		Info:              Bold + BrightCyan,
+		Default:           Bold + BrightWhite,
+		Method:            Bold + BrightWhite,
+		StatusSuccess:     Bold + BrightGreen,
+		StatusRedirect:    Bold + BrightCyan,
+		StatusClientError: Bold + BrightYellow,
+		StatusServerError: Bold + BrightRed,
+		TableBorder:       Bold + BrightWhite,
+		TableTitle:        Bold + BrightWhite,
+		Key:               Bold + BrightCyan,
+		Value:             BrightWhite,
+		BodyBorder:        Bold + BrightWhite,
+		BodyTitle:         Bold + BrightWhite,
+		JSONKey:           Bold + BrightCyan,
+		JSONString:        BrightGreen,
+		JSONNumber:        BrightMagenta,
+		JSONBool:          BrightYellow,
+		JSONNull:          BrightRed,
+		JSONPunctuation:   BrightWhite,
+	}
+
+	// MonochromeTheme uses no colors, only bold and underlined text for
+	// emphasis
+	MonochromeTheme = Theme{
+		Method:            Bold,
+		StatusClientError: Bold,
+		StatusServerError: Bold + Underline,
+		TableTitle:        Bold,
+		Key:               Bold,
+		BodyTitle:         Bold,
+		JSONKey:           Bold,
+	}
+)
+
+// palette returns the theme, DarkTheme for the zero value
+func (p *ConsolePrinter) palette() Theme {
+	if p.theme == (Theme{}) {
+		return DarkTheme
+	}
+	return p.theme
+}
+
+// paint renders s with the sequence code, plain when colors are disabled
+func (p *ConsolePrinter) paint(code, s string) string {
+	return styles.NewStyle().Foreground(p.ansi(code)).Render(s)
+}
+
+// boxColor returns the border sequence for the color names the renderer
+// passes to PrintBox, the theme default for other names
+func (p *ConsolePrinter) boxColor(color string) string {
+	t := p.palette()
+	switch strings.ToLower(color) {
+	case "blue":
+		return t.Request
+	case "green":
+		return t.Success
+	case "red":
+		return t.Failure
+	case "yellow":
+		return t.Warning
+	case "cyan":
+		return t.Info
+	default:
+		return t.Default
+	}
+}
+
+// highlightMethod colors the request method in a box header
+func (p *ConsolePrinter) highlightMethod(method string) string {
+	return p.paint(p.palette().Method, method)
+}
+
+// highlightStatus colors a status in a box header by its class
+func (p *ConsolePrinter) highlightStatus(code int, status string) string {
+	t := p.palette()
+	switch {
+	case code >= 500:
+		return p.paint(t.StatusServerError, status)
+	case code >= 400:
+		return p.paint(t.StatusClientError, status)
+	case code >= 300:
+		return p.paint(t.StatusRedirect, status)
+	default:
+		return p.paint(t.StatusSuccess, status)
+	}
+}
@@ -0,0 +1,125 @@
+package reqpretty
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// StatusRange is an inclusive range of HTTP status codes
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+// StatusClass returns the range covering a status class, e.g. StatusClass(4)
+// covers 400-499
+func StatusClass(class int) StatusRange {
+	return StatusRange{Min: class * 100, Max: class*100 + 99}
+}
+
+// Contains reports whether status falls within the range
+func (s StatusRange) Contains(status int) bool {
+	return status >= s.Min && status <= s.Max
+}
+
+// matchRequest reports whether r passes the path and method filters, which
+// are known before the handler runs
+func (l *exchangeLogger) matchRequest(r *http.Request) bool {
+	opts := l.opts
+	p := r.URL.Path
+
+	if len(opts.IncludePaths) > 0 && !matchAnyGlob(opts.IncludePaths, p) {
+		return false
+	}
+	if matchAnyGlob(opts.ExcludePaths, p) {
+		return false
+	}
+	if len(opts.Methods) > 0 && !containsFold(opts.Methods, r.Method) {
+		return false
+	}
+	return true
+}
+
+// wants reports whether an exchange passes the filters that can be decided
+// once the status is known, everything except MinDuration. A status of zero
+// means an outbound request failed without a response, which StatusRanges
+// lets through.
+func (l *exchangeLogger) wants(r *http.Request, status int) bool {
+	opts := l.opts
+	if !l.matchRequest(r) {
+		return false
+	}
+	if len(opts.StatusRanges) > 0 && status != 0 {
+		matched := false
+		for _, sr := range opts.StatusRanges {
+			if sr.Contains(status) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if opts.Filter != nil && !opts.Filter(r, status) {
+		return false
+	}
+	return true
+}
+
+// shouldLog reports whether a finished exchange passes every filter
+func (l *exchangeLogger) shouldLog(r *http.Request, status int, duration time.Duration) bool {
+	return duration >= l.opts.MinDuration && l.wants(r, status)
+}
+
+// matchAnyGlob reports whether p matches any of the patterns
+func matchAnyGlob(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a URL path against a path.Match pattern, where a "**"
+// segment matches any number of path segments
+func matchGlob(pattern, p string) bool {
+	seg, rest, more := strings.Cut(pattern, "/")
+	if seg == "**" {
+		if !more {
+			return true
+		}
+		for {
+			if matchGlob(rest, p) {
+				return true
+			}
+			var ok bool
+			if _, p, ok = strings.Cut(p, "/"); !ok {
+				return false
+			}
+		}
+	}
+
+	name, remaining, hasMore := strings.Cut(p, "/")
+	if ok, err := path.Match(seg, name); err != nil || !ok {
+		return false
+	}
+	if more != hasMore {
+		// A trailing "**" also matches no segments at all
+		return more && rest == "**"
+	}
+	return !more || matchGlob(rest, remaining)
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
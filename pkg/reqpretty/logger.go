@@ -1,157 +1,138 @@
 package reqpretty
 
 import (
-	"fmt"
 	"log/slog"
 	"net/http"
-	"runtime/debug"
-	"strings"
 	"time"
 
 	"github.com/1saifj/reqpretty/pkg/printer"
 )
 
-// logRequest logs the request details in beautiful format
-func logRequest(r *http.Request, reqBody []byte, opts Options) {
-	if !opts.IncludeRequest {
-		return
-	}
-
-	// Extract context attributes
-	ctx := r.Context()
-	contextAttrs := extractContextAttributes(ctx, opts.ContextAttributes)
-
-	// Print request header
-	printRequestHeader(r, opts.Printer)
-
-	// Print context attributes if any
-	if len(contextAttrs) > 0 {
-		printContextAttributes(contextAttrs, opts.Printer)
-	}
-
-	// Print query parameters
-	if opts.IncludeRequestQueryParams && len(r.URL.Query()) > 0 {
-		opts.Printer.PrintTable(convertURLValuesToMap(r.URL.Query()), "Query Parameters")
-	}
-
-	// Print headers
-	if opts.IncludeRequestHeaders {
-		opts.Printer.PrintTable(convertHeadersToMap(r.Header), "Headers")
-	}
-
-	// Print body
-	if opts.IncludeRequestBody && len(reqBody) > 0 {
-		opts.Printer.PrintBody(reqBody, "Request Body")
-	}
+// exchangeLogger holds the options and compiled rules shared by the server
+// middleware and the client transport
+type exchangeLogger struct {
+	opts     Options
+	redactor *redactor
+	sampler  *sampler
 }
 
-// logResponse logs the response details in beautiful format
-func logResponse(rec *responseWriter, duration time.Duration, opts Options) {
-	if !opts.IncludeResponse {
-		return
+// newExchangeLogger picks the printer, compiles the redaction
+// rules and sets up sampling. It panics when a body rule is invalid rather
+// than log the fields it was meant to hide.
+func newExchangeLogger(opts Options) exchangeLogger {
+	if opts.Logger != nil {
+		opts.Printer = printer.NewSlogPrinter(opts.Logger)
 	}
-
-	// Print response header
-	printResponseHeader(rec, duration, opts)
-
-	// Print response headers
-	if opts.IncludeResponseHeaders {
-		opts.Printer.PrintTable(convertHeadersToMap(rec.Header()), "Response Headers")
+	if opts.Printer == nil {
+		opts.Printer = printer.NewConsolePrinter()
 	}
-
-	// Print response body
-	if opts.IncludeResponseBody && len(rec.body) > 0 {
-		opts.Printer.PrintBody(rec.body, "Response Body")
+	red, err := newRedactor(opts)
+	if err != nil {
+		panic(err)
 	}
-
-	// Add spacing after response
-	fmt.Println()
+	return exchangeLogger{opts: opts, redactor: red, sampler: newSampler(opts)}
 }
 
-// printRequestHeader prints a beautiful request header
-func printRequestHeader(r *http.Request, printer printer.Printer) {
-	method := r.Method
-	url := r.URL.String()
-
-	header := fmt.Sprintf("Request - %s", method)
-	printer.PrintBox(header, url, "blue")
+// passthrough reports whether r can skip logging entirely, because nothing
+// would be printed or the path and method filters already exclude it
+func (l *exchangeLogger) passthrough(r *http.Request) bool {
+	return (!l.opts.IncludeRequest && !l.opts.IncludeResponse) || !l.matchRequest(r)
 }
 
-// printResponseHeader prints a beautiful response header
-func printResponseHeader(rec *responseWriter, duration time.Duration, opts Options) {
-	statusEmoji := opts.SuccessEmoji
-	statusColor := "green"
+// capturesRequestBody reports whether request bodies are printed
+func (l *exchangeLogger) capturesRequestBody() bool {
+	return l.opts.IncludeRequest && l.opts.IncludeRequestBody
+}
 
-	if rec.statusCode >= 400 {
-		statusEmoji = opts.ErrorEmoji
-		statusColor = "red"
+// responseCapture returns the capture for a response body, which only
+// counts the size when response bodies are not printed
+func (l *exchangeLogger) responseCapture() bodyCapture {
+	return bodyCapture{
+		limit:   l.opts.MaxResponseBodyBytes,
+		discard: !l.opts.IncludeResponse || !l.opts.IncludeResponseBody,
 	}
+}
 
-	if statusEmoji == "" {
-		if rec.statusCode >= 400 {
-			statusEmoji = "❌"
-		} else {
-			statusEmoji = "✅"
+// newExchange collects the parts of a server-side exchange enabled by opts
+func (h *debugHandler) newExchange(r *http.Request, reqBody *requestBody, rec *responseWriter, startTime time.Time, duration time.Duration, pnc *printer.Panic) printer.Exchange {
+	e := printer.Exchange{
+		Time:      startTime,
+		Duration:  duration,
+		Panic:     pnc,
+		RequestID: RequestIDFromContext(r.Context()),
+		Context:   r.Context(),
+	}
+	if h.opts.IncludeTrace {
+		e.TraceID, e.SpanID, e.TraceURL = h.traceIDs(r)
+	}
+	e.Request, e.Attrs = h.newRequest(r, reqBody)
+
+	if h.opts.IncludeResponse {
+		resp := h.newResponse(rec.statusCode, rec.Header(), &rec.body)
+		if rec.hijacked || rec.statusCode == http.StatusSwitchingProtocols {
+			// The handler took over the connection, the recorded status is meaningless
+			resp.StatusCode = http.StatusSwitchingProtocols
+			resp.Upgraded = true
 		}
+		if rec.stream != nil {
+			resp.Stream = rec.stream.summary
+		}
+		e.Response = resp
 	}
 
-	status := fmt.Sprintf("%d %s", rec.statusCode, http.StatusText(rec.statusCode))
-	timeStr := duration.String()
+	if h.opts.IncludeTiming {
+		e.Timing = rec.timing(startTime, duration)
+	}
 
-	header := fmt.Sprintf("%s Response - Status: %s - Time: %s", statusEmoji, status, timeStr)
-	opts.Printer.PrintBox(header, "", statusColor)
+	return e
 }
 
-// printContextAttributes prints context attributes in a table
-func printContextAttributes(attrs []slog.Attr, printer printer.Printer) {
-	if len(attrs) == 0 {
-		return
+// newRequest collects the parts of the request enabled by opts along with
+// the context attributes, returning nil when requests are not logged
+func (l *exchangeLogger) newRequest(r *http.Request, reqBody *requestBody) (*printer.Request, []slog.Attr) {
+	opts := l.opts
+	if !opts.IncludeRequest {
+		return nil, nil
 	}
 
-	contextMap := make(map[string]interface{})
-	for _, attr := range attrs {
-		contextMap[attr.Key] = attr.Value.Any()
+	attrs := extractContextAttributes(r.Context(), opts)
+	req := &printer.Request{
+		Method: r.Method,
+		URL:    l.redactor.redactURL(r.URL),
 	}
-	printer.PrintTable(contextMap, "Context Attributes")
-}
-
-// logPanic prints panic details in a beautiful box
-func logPanic(rcv interface{}, printer printer.Printer) {
-	errorMsg := fmt.Sprintf("💥 PANIC RECOVERED 💥\n\nError: %v\n", rcv)
-	// Just a portion of the stack to avoid huge logs
-	stack := string(debug.Stack())
-	lines := strings.Split(stack, "\n")
-	if len(lines) > 15 {
-		lines = lines[:15]
-		lines = append(lines, "...")
-	}
-	stackMsg := fmt.Sprintf("Stack Trace (truncated):\n%s", strings.Join(lines, "\n"))
-	content := errorMsg + "\n" + stackMsg
-
-	printer.PrintBox("PANIC", content, "red")
-}
-
-// Helper functions to convert types
-func convertHeadersToMap(headers http.Header) map[string]interface{} {
-	result := make(map[string]interface{})
-	for key, values := range headers {
-		if len(values) == 1 {
-			result[key] = values[0]
-		} else {
-			result[key] = values
-		}
+	if opts.IncludeRequestQueryParams {
+		req.Query = l.redactor.redactQuery(r.URL.Query())
+	}
+	if opts.IncludeRequestHeaders {
+		req.Headers = l.redactor.redactHeaders(r.Header)
+	}
+	if opts.IncludeRequestBody {
+		req.BodySize = max(reqBody.total, r.ContentLength)
+		req.BodyRead = reqBody.readState()
+		// A streamed body of unknown length the handler stopped reading
+		// is cut short even though every byte read was kept
+		req.BodyTruncated = req.BodySize > int64(len(reqBody.buf)) ||
+			req.BodyRead == printer.BodyPartiallyRead || req.BodyRead == printer.BodyNotRead
+		req.Body = l.redactor.redactBody(reqBody.buf, req.BodyTruncated)
 	}
-	return result
+	return req, attrs
 }
 
-func convertURLValuesToMap(values map[string][]string) map[string]interface{} {
-	result := make(map[string]interface{})
-	for key, vals := range values {
-		if len(vals) == 1 {
-			result[key] = vals[0]
-		} else {
-			result[key] = vals
-		}
+// newResponse collects the parts of the response enabled by opts
+func (l *exchangeLogger) newResponse(statusCode int, headers http.Header, body *bodyCapture) *printer.Response {
+	opts := l.opts
+	resp := &printer.Response{StatusCode: statusCode}
+	if opts.IncludeResponseHeaders {
+		resp.Headers = l.redactor.redactHeaders(headers)
+	}
+	if opts.IncludeResponseBody {
+		resp.BodySize = body.total
+		resp.BodyTruncated = body.cut || resp.BodySize > int64(len(body.buf))
+		resp.Body = l.redactor.redactBody(body.buf, resp.BodyTruncated)
+	}
+	resp.Emoji = opts.SuccessEmoji
+	if statusCode >= 400 {
+		resp.Emoji = opts.ErrorEmoji
 	}
-	return result
+	return resp
 }
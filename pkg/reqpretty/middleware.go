@@ -4,9 +4,11 @@ package reqpretty
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"runtime/debug"
 	"time"
 
 	"github.com/1saifj/reqpretty/pkg/printer"
@@ -15,40 +17,205 @@ import (
 // DebugHandlerFunc is a function type for middleware
 type DebugHandlerFunc func(opts Options, next http.Handler) http.Handler
 
-// DebugHandler wraps an http.Handler with debug logging
+// debugHandler holds the state shared by every request through one DebugHandler
+type debugHandler struct {
+	exchangeLogger
+	next http.Handler
+}
+
+// DebugHandler wraps an http.Handler with debug logging. Requests excluded
+// by the path and method filters, or when neither requests nor responses
+// are logged, go straight to next without being wrapped, and bodies are
+// only captured when they are printed. A panic in next is recovered and
+// printed either way. It panics when RedactBodyFields holds an invalid path.
 func DebugHandler(opts Options, next http.Handler) http.Handler {
-	if opts.Printer == nil {
-		opts.Printer = printer.NewConsolePrinter()
+	return &debugHandler{exchangeLogger: newExchangeLogger(opts), next: next}
+}
+
+func (h *debugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.RequestID {
+		// Assigned even when the exchange is not logged, handlers rely on it
+		r = h.assignRequestID(w, r)
 	}
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		startTime := time.Now()
+	if h.passthrough(r) {
+		defer h.recoverPassthrough(w, r, time.Now())
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	startTime := time.Now()
 
-		// Capture the request body and restore it for further processing
-		reqBody, err := readAndRestoreBody(r.Body)
+	// Capture the request body and restore it for further processing
+	var reqBody *requestBody
+	if h.capturesRequestBody() {
+		var err error
+		reqBody, err = captureRequestBody(r, h.opts.MaxRequestBodyBytes, h.opts.StreamRequestBody)
 		if err != nil {
 			slog.Error("Error reading request body", "error", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
-		r.Body = io.NopCloser(bytes.NewBuffer(reqBody)) // Restore the body
+	}
 
-		rec := newRecorder(w)
+	sampled := h.sampleOnce()
+	rec := newRecorder(w, h.responseCapture())
+	if h.opts.IncludeResponse {
+		rec.stream = h.newStreamRecorder(r, rec, startTime, sampled)
+	}
 
-		defer func() {
-			duration := time.Since(startTime)
+	defer func() {
+		duration := time.Since(startTime)
 
-			if rcv := recover(); rcv != nil {
-				logPanic(rcv, opts.Printer)
+		var pnc *printer.Panic
+		if rcv := recover(); rcv != nil {
+			pnc = &printer.Panic{Value: rcv, Stack: debug.Stack()}
+			if !rec.hijacked {
 				rec.WriteHeader(http.StatusInternalServerError)
 			}
+		}
 
-			// Always log the request and response
-			logRequest(r, reqBody, opts)
-			logResponse(rec, duration, opts)
-		}()
+		if rec.stream != nil {
+			rec.stream.finish()
+		}
 
-		next.ServeHTTP(rec, r)
-	})
+		// A recovered panic is printed whatever the filters decide
+		if pnc == nil && !h.shouldLog(r, rec.statusCode, duration) {
+			return
+		}
+		if !h.admit(sampled, rec.statusCode, pnc != nil, pnc != nil, duration) {
+			return
+		}
+		h.opts.Printer.PrintExchange(h.newExchange(r, reqBody, rec, startTime, duration, pnc))
+	}()
+
+	h.next.ServeHTTP(rec.wrap(), r)
+}
+
+// recoverPassthrough recovers a panic in a request that was not wrapped,
+// answers 500 and prints the panic like any other recovered one
+func (h *debugHandler) recoverPassthrough(w http.ResponseWriter, r *http.Request, startTime time.Time) {
+	rcv := recover()
+	if rcv == nil {
+		return
+	}
+	pnc := &printer.Panic{Value: rcv, Stack: debug.Stack()}
+	rec := newRecorder(w, h.responseCapture())
+	rec.WriteHeader(http.StatusInternalServerError)
+
+	reqBody := &requestBody{length: r.ContentLength}
+	h.opts.Printer.PrintExchange(h.newExchange(r, reqBody, rec, startTime, time.Since(startTime), pnc))
+}
+
+// newStreamRecorder reports the events of a streamed response through the
+// printer when it supports incremental output and response bodies are
+// logged, and returns nil otherwise so the body is captured as usual.
+// Events are filtered on everything but the final duration and share the
+// sampling decision of the exchange.
+func (h *debugHandler) newStreamRecorder(r *http.Request, rec *responseWriter, startTime time.Time, sampled func() bool) *streamRecorder {
+	sp, ok := h.opts.Printer.(printer.StreamPrinter)
+	if !ok || !h.opts.IncludeResponseBody {
+		return nil
+	}
+	s := &streamRecorder{start: startTime, limit: h.opts.MaxResponseBodyBytes}
+	url := h.redactor.redactURL(r.URL)
+	s.emit = func(ev printer.StreamEvent) {
+		if !h.wants(r, rec.statusCode) || !sampled() {
+			return
+		}
+		ev.Method = r.Method
+		ev.URL = url
+		// Flushes split bodies anywhere, so a chunk is redacted as if cut
+		ev.Chunk = h.redactor.redactBody(ev.Chunk, true)
+		if ev.Data != "" {
+			ev.Data = string(h.redactor.redactBody([]byte(ev.Data), ev.Truncated))
+		}
+		sp.PrintStreamEvent(ev)
+	}
+	return s
+}
+
+// requestBody is what DebugHandler captured of the request body
+type requestBody struct {
+	bodyCapture
+
+	// streamed is set when the body was recorded as the handler read it
+	streamed bool
+	// eof is set once the handler has read the streamed body to the end
+	eof bool
+	// length is the declared Content-Length, -1 when unknown
+	length int64
+}
+
+// readState reports how much of a streamed body the handler consumed
+func (b *requestBody) readState() printer.BodyReadState {
+	switch {
+	case !b.streamed:
+		return printer.BodyReadUnknown
+	case b.eof, b.length >= 0 && b.total >= b.length && b.total > 0:
+		// Decoders such as json.Decoder stop at the last byte without
+		// reading on to EOF
+		return printer.BodyFullyRead
+	case b.total > 0:
+		return printer.BodyPartiallyRead
+	default:
+		return printer.BodyNotRead
+	}
+}
+
+// captureRequestBody reads the request body and restores it for further
+// processing. With a positive limit only limit+1 bytes are read up front,
+// the rest streams through to the handler and is counted as it is consumed.
+// When stream is set nothing is read up front, the body is recorded as the
+// handler reads it.
+func captureRequestBody(r *http.Request, limit int64, stream bool) (*requestBody, error) {
+	body := &requestBody{bodyCapture: bodyCapture{limit: limit}, length: r.ContentLength}
+	if r.Body == nil || r.Body == http.NoBody {
+		return body, nil
+	}
+
+	if stream {
+		body.streamed = true
+		r.Body = &streamBody{ReadCloser: r.Body, body: body}
+		return body, nil
+	}
+
+	if limit <= 0 {
+		buf, err := readAndRestoreBody(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		body.Write(buf)
+		r.Body = io.NopCloser(bytes.NewBuffer(buf)) // Restore the body
+		return body, nil
+	}
+
+	// One byte past the limit tells a cut body from one that fits exactly,
+	// even when the handler never reads on and the length is unknown
+	prefix, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		r.Body.Close()
+		return nil, err
+	}
+	body.Write(prefix)
+	r.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(prefix), io.TeeReader(r.Body, &body.bodyCapture)),
+		Closer: r.Body,
+	}
+	return body, nil
+}
+
+// streamBody records what the handler reads from the request body
+type streamBody struct {
+	io.ReadCloser
+	body *requestBody
+}
+
+func (s *streamBody) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	s.body.Write(p[:n])
+	if err == io.EOF {
+		s.body.eof = true
+	}
+	return n, err
 }
 
 // readAndRestoreBody reads the request body and restores it for further processing
@@ -64,13 +231,34 @@ func readAndRestoreBody(body io.ReadCloser) ([]byte, error) {
 	return buf, nil
 }
 
-// extractContextAttributes extracts specified attributes from context
-func extractContextAttributes(ctx context.Context, attributes []string) []slog.Attr {
+// readCloser combines a reader with the closer of the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// extractContextAttributes extracts the configured attributes from context
+func extractContextAttributes(ctx context.Context, opts Options) []slog.Attr {
 	var attrs []slog.Attr
-	for _, attrName := range attributes {
+	for _, attrName := range opts.ContextAttributes {
 		if attrValue := ctx.Value(attrName); attrValue != nil {
 			attrs = append(attrs, slog.Any(attrName, attrValue))
 		}
 	}
+	for _, key := range opts.ContextKeys {
+		if attrValue := ctx.Value(key.Key); attrValue != nil {
+			name := key.Name
+			if name == "" {
+				// Typed keys are usually empty structs, print their type
+				name = fmt.Sprintf("%T", key.Key)
+			}
+			attrs = append(attrs, slog.Any(name, attrValue))
+		}
+	}
+	for _, extract := range opts.ContextExtractors {
+		if attr, ok := extract(ctx); ok {
+			attrs = append(attrs, attr)
+		}
+	}
 	return attrs
 }
@@ -1,6 +1,20 @@
 package reqpretty
 
-import "github.com/1saifj/reqpretty/pkg/printer"
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/1saifj/reqpretty/pkg/printer"
+)
+
+// ContextKey is a context key to log under a display name, or under the
+// type of the key when Name is empty
+type ContextKey struct {
+	Key  any
+	Name string
+}
 
 // Options configures the debug middleware behavior
 type Options struct {
@@ -15,8 +29,94 @@ type Options struct {
 	IncludeResponseHeaders bool
 	IncludeResponseBody    bool
 
-	// Context attributes to log
+	// Maximum number of body bytes kept for logging, zero means unlimited.
+	// The handler and client still see the complete body.
+	MaxRequestBodyBytes  int64
+	MaxResponseBodyBytes int64
+
+	// StreamRequestBody records the request body as the handler reads it,
+	// or for Transport as the base RoundTripper sends it, instead of
+	// reading it up front, which keeps streaming uploads and
+	// http.MaxBytesReader working. The log reports how much was read.
+	StreamRequestBody bool
+
+	// IncludeTiming breaks the duration down into phases: handler think
+	// time, time to first byte and body write time for DebugHandler, and DNS
+	// lookup, connect, TLS handshake and time to first byte for outbound
+	// requests made through Transport
+	IncludeTiming bool
+
+	// Context attributes to log. ContextAttributes looks values up under
+	// plain string keys, ContextKeys under any key value such as the typed
+	// keys used by routers and tracing libraries, and ContextExtractors can
+	// compute attributes from the context directly.
 	ContextAttributes []string
+	ContextKeys       []ContextKey
+	ContextExtractors []func(ctx context.Context) (slog.Attr, bool)
+
+	// Redaction rules, matching values are replaced with RedactMask.
+	// Header and query parameter names are case-insensitive. Body fields are
+	// JSON paths such as "$.password", "$.card.number", "$.items[*].token"
+	// or "$..secret"; a bare name like "password" matches at any depth. An
+	// invalid path makes DebugHandler and Transport panic.
+	RedactHeaders     []string
+	RedactQueryParams []string
+	RedactBodyFields  []string
+	RedactMask        string
+
+	// Filters, evaluated once the response is known so status and duration
+	// can be used. An exchange is logged only when it passes all of them.
+	// Paths are path.Match globs where "**" matches any number of segments,
+	// e.g. "/api/**"; empty lists match everything. Outbound requests that
+	// failed without a response always pass StatusRanges and reach Filter
+	// with status 0. A handler panic recovered by DebugHandler is printed
+	// regardless of the filters.
+	IncludePaths []string
+	ExcludePaths []string
+	Methods      []string
+	StatusRanges []StatusRange
+	MinDuration  time.Duration
+	Filter       func(r *http.Request, status int) bool
+
+	// Sampling of exchanges that passed the filters. SampleRate logs that
+	// fraction of exchanges, zero or one logs all of them, and MaxPerSecond
+	// caps the logged exchanges with a token bucket. AlwaysLogErrors and
+	// SlowThreshold let failed (status 400 and up, panics, transport errors)
+	// and slow exchanges through regardless; recovered panics are printed
+	// even without AlwaysLogErrors. When SummaryInterval is set, the number
+	// of suppressed exchanges is reported one interval after the first of
+	// them, and so at most once per interval.
+	SampleRate      float64
+	MaxPerSecond    int
+	AlwaysLogErrors bool
+	SlowThreshold   time.Duration
+	SummaryInterval time.Duration
+
+	// RequestID reads the request ID from RequestIDHeader (X-Request-ID
+	// when empty) or generates one with RequestIDGenerator (NewUUID when
+	// nil), stores it in the context for RequestIDFromContext, echoes it in
+	// the response header and shows it in the request and response boxes.
+	// Transport sets the header on outgoing requests, reusing the ID of the
+	// request being served when there is one.
+	RequestID          bool
+	RequestIDHeader    string
+	RequestIDGenerator func() string
+
+	// IncludeTrace shows the trace and span IDs of the exchange, taken from
+	// TraceContext when it reports the active span and otherwise from the
+	// W3C traceparent header. TraceContext keeps tracing libraries out of
+	// this package, with OpenTelemetry it would be
+	//
+	//	func(ctx context.Context) (string, string, bool) {
+	//		sc := trace.SpanContextFromContext(ctx)
+	//		return sc.TraceID().String(), sc.SpanID().String(), sc.IsValid()
+	//	}
+	//
+	// TraceLinkTemplate renders a link to the trace with {trace_id} and
+	// {span_id} replaced, e.g. "http://localhost:16686/trace/{trace_id}".
+	IncludeTrace      bool
+	TraceContext      func(ctx context.Context) (traceID, spanID string, ok bool)
+	TraceLinkTemplate string
 
 	// Custom emojis for status indication
 	SuccessEmoji string
@@ -24,6 +124,10 @@ type Options struct {
 
 	// Printer interface for customizable output formatting
 	Printer printer.Printer
+
+	// Logger emits each exchange as a slog record through printer.SlogPrinter
+	// instead, taking precedence over Printer
+	Logger *slog.Logger
 }
 
 // DefaultOptions returns sensible default options
@@ -36,7 +140,12 @@ func DefaultOptions() Options {
 		IncludeResponse:           true,
 		IncludeResponseHeaders:    true,
 		IncludeResponseBody:       true,
+		IncludeTiming:             true,
+		IncludeTrace:              true,
 		ContextAttributes:         []string{},
+		RedactHeaders:             []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+		RedactMask:                defaultRedactMask,
+		RequestIDHeader:           defaultRequestIDHeader,
 		SuccessEmoji:              "✅",
 		ErrorEmoji:                "❌",
 		Printer:                   printer.NewConsolePrinter(),
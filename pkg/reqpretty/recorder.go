@@ -1,24 +1,235 @@
 package reqpretty
 
-import "net/http"
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/1saifj/reqpretty/pkg/printer"
+)
 
 // responseWriter wraps http.ResponseWriter to capture the status code and body
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
-	body       []byte
+	body       bodyCapture
+	hijacked   bool
+
+	// stream is nil when streamed responses are not reported incrementally
+	stream *streamRecorder
+
+	// headerAt is when the handler first wrote the header or body,
+	// firstWriteAt and lastWriteAt bracket the body writes and flushes
+	headerAt     time.Time
+	firstWriteAt time.Time
+	lastWriteAt  time.Time
 }
 
-func newRecorder(w http.ResponseWriter) *responseWriter {
-	return &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+func newRecorder(w http.ResponseWriter, body bodyCapture) *responseWriter {
+	return &responseWriter{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+		body:           body,
+	}
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
+	if rw.headerAt.IsZero() {
+		rw.headerAt = time.Now()
+	}
 	rw.statusCode = code
+	if code >= 200 {
+		rw.checkStream()
+	}
 	rw.ResponseWriter.WriteHeader(code)
 }
 
 func (rw *responseWriter) Write(p []byte) (int, error) {
-	rw.body = append(rw.body, p...) // Capture response body
+	rw.markWrite()
+	if s := rw.stream; s != nil {
+		rw.checkStream()
+		if s.active {
+			// Streamed bodies are reported as they go instead of being kept
+			rw.body.total += int64(len(p))
+			s.write(p)
+			return rw.ResponseWriter.Write(p)
+		}
+	}
+	rw.body.Write(p) // Capture response body
 	return rw.ResponseWriter.Write(p)
 }
+
+// checkStream starts an event stream when the response is sent as
+// text/event-stream. The header is frozen once sent, so the content type is
+// only looked at the first time.
+func (rw *responseWriter) checkStream() {
+	s := rw.stream
+	if s == nil || s.checked {
+		return
+	}
+	s.checked = true
+	if !s.active && isEventStream(rw.Header()) {
+		s.begin(true, nil, 0)
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// markWrite timestamps bytes being sent to the client
+func (rw *responseWriter) markWrite() {
+	now := time.Now()
+	if rw.headerAt.IsZero() {
+		rw.headerAt = now
+	}
+	if rw.firstWriteAt.IsZero() {
+		rw.firstWriteAt = now
+	}
+	rw.lastWriteAt = now
+}
+
+// timing splits the handler time into think time before the response
+// started, time to first byte and the time spent writing the body
+func (rw *responseWriter) timing(startTime time.Time, duration time.Duration) *printer.Timing {
+	t := &printer.Timing{Total: duration}
+	if !rw.headerAt.IsZero() {
+		t.Phases = append(t.Phases, printer.TimingPhase{
+			Name:     "Handler think time",
+			Duration: rw.headerAt.Sub(startTime),
+		})
+	}
+	if !rw.firstWriteAt.IsZero() {
+		t.TimeToFirstByte = rw.firstWriteAt.Sub(startTime)
+		t.Phases = append(t.Phases, printer.TimingPhase{
+			Name:     "Body write",
+			Start:    t.TimeToFirstByte,
+			Duration: rw.lastWriteAt.Sub(rw.firstWriteAt),
+		})
+	}
+	return t
+}
+
+func (rw *responseWriter) flush() {
+	rw.markWrite()
+	if s := rw.stream; s != nil {
+		rw.checkStream()
+		if !s.active {
+			s.begin(false, rw.body.buf, rw.body.total)
+			rw.body.buf = nil
+		}
+		s.flush()
+	}
+	rw.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (rw *responseWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := rw.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		rw.hijacked = true
+	}
+	return conn, brw, err
+}
+
+func (rw *responseWriter) push(target string, opts *http.PushOptions) error {
+	return rw.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+type flusher struct{ rw *responseWriter }
+
+func (f flusher) Flush() { f.rw.flush() }
+
+type hijacker struct{ rw *responseWriter }
+
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) { return h.rw.hijack() }
+
+type pusher struct{ rw *responseWriter }
+
+func (p pusher) Push(target string, opts *http.PushOptions) error { return p.rw.push(target, opts) }
+
+// wrap returns a writer around rw that implements exactly the optional
+// interfaces supported by the underlying writer, so type assertions for
+// http.Flusher, http.Hijacker and http.Pusher behave as if unwrapped
+func (rw *responseWriter) wrap() http.ResponseWriter {
+	_, canFlush := rw.ResponseWriter.(http.Flusher)
+	_, canHijack := rw.ResponseWriter.(http.Hijacker)
+	_, canPush := rw.ResponseWriter.(http.Pusher)
+
+	switch {
+	case canFlush && canHijack && canPush:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rw, flusher{rw}, hijacker{rw}, pusher{rw}}
+	case canFlush && canHijack:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+		}{rw, flusher{rw}, hijacker{rw}}
+	case canFlush && canPush:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Pusher
+		}{rw, flusher{rw}, pusher{rw}}
+	case canHijack && canPush:
+		return struct {
+			*responseWriter
+			http.Hijacker
+			http.Pusher
+		}{rw, hijacker{rw}, pusher{rw}}
+	case canFlush:
+		return struct {
+			*responseWriter
+			http.Flusher
+		}{rw, flusher{rw}}
+	case canHijack:
+		return struct {
+			*responseWriter
+			http.Hijacker
+		}{rw, hijacker{rw}}
+	case canPush:
+		return struct {
+			*responseWriter
+			http.Pusher
+		}{rw, pusher{rw}}
+	default:
+		return rw
+	}
+}
+
+// bodyCapture keeps up to limit bytes of a body while counting its total
+// size. A limit of zero or less keeps everything, discard keeps nothing.
+type bodyCapture struct {
+	buf     []byte
+	limit   int64
+	total   int64
+	discard bool
+
+	// cut is set when the reader stopped before the end of the body
+	cut bool
+}
+
+func (c *bodyCapture) Write(p []byte) (int, error) {
+	n := len(p)
+	c.total += int64(n)
+	if c.discard {
+		return n, nil
+	}
+	if c.limit > 0 {
+		room := c.limit - int64(len(c.buf))
+		if room <= 0 {
+			return n, nil
+		}
+		if int64(len(p)) > room {
+			p = p[:room]
+		}
+	}
+	c.buf = append(c.buf, p...)
+	return n, nil
+}
@@ -0,0 +1,395 @@
+package reqpretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultRedactMask replaces redacted values when Options.RedactMask is empty
+const defaultRedactMask = "[REDACTED]"
+
+// redactor applies the redaction rules from Options to headers, query
+// parameters and JSON bodies
+type redactor struct {
+	headers map[string]bool
+	query   map[string]bool
+	paths   [][]pathSegment
+	mask    string
+}
+
+// newRedactor compiles the redaction rules, reporting every body path that
+// cannot be parsed
+func newRedactor(opts Options) (*redactor, error) {
+	r := &redactor{
+		headers: make(map[string]bool),
+		query:   make(map[string]bool),
+		mask:    opts.RedactMask,
+	}
+	if r.mask == "" {
+		r.mask = defaultRedactMask
+	}
+	for _, name := range opts.RedactHeaders {
+		r.headers[strings.ToLower(name)] = true
+	}
+	for _, name := range opts.RedactQueryParams {
+		r.query[strings.ToLower(name)] = true
+	}
+	var errs []error
+	for _, path := range opts.RedactBodyFields {
+		segments, err := parsePath(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.paths = append(r.paths, segments)
+	}
+	return r, errors.Join(errs...)
+}
+
+// redactHeaders returns a copy of headers with sensitive values masked
+func (r *redactor) redactHeaders(headers http.Header) http.Header {
+	result := headers.Clone()
+	for key, values := range result {
+		if r.headers[strings.ToLower(key)] {
+			result[key] = r.maskAll(values)
+		}
+	}
+	return result
+}
+
+// redactQuery returns a copy of values with sensitive parameters masked
+func (r *redactor) redactQuery(values url.Values) url.Values {
+	result := make(url.Values, len(values))
+	for key, vals := range values {
+		if r.query[strings.ToLower(key)] {
+			result[key] = r.maskAll(vals)
+		} else {
+			result[key] = append([]string(nil), vals...)
+		}
+	}
+	return result
+}
+
+// redactURL masks the password and sensitive query parameters in the URL,
+// keeping the order and encoding of everything else as sent
+func (r *redactor) redactURL(u *url.URL) string {
+	if len(r.query) == 0 || u.RawQuery == "" {
+		return u.Redacted()
+	}
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if r.query[strings.ToLower(name)] {
+			pairs[i] = key + "=" + r.mask
+		}
+	}
+	redacted := *u
+	redacted.RawQuery = strings.Join(pairs, "&")
+	return redacted.Redacted()
+}
+
+// redactBody masks the matching fields of a JSON body. A body holding a
+// sequence of JSON values, such as NDJSON, has each value redacted and is
+// returned one value per line. Bodies that are not JSON or contain no
+// matching fields are returned unchanged, while a body that turns out not
+// to be JSON after its first value is replaced with the mask as a whole.
+// A truncated JSON body is returned up to the last complete token, so a
+// secret cut in half by a size limit is never shown.
+func (r *redactor) redactBody(body []byte, truncated bool) []byte {
+	if len(r.paths) == 0 || len(body) == 0 {
+		return body
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	redacted := false
+	for i := 0; i == 0 || dec.More(); i++ {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		changed, err := r.copyValue(dec, nil, &buf)
+		switch {
+		case err != nil && truncated && buf.Len() > 0:
+			return buf.Bytes()
+		case err != nil && i == 0:
+			return body
+		case err != nil:
+			// Trailing data that is not JSON, which may hide anything
+			return []byte(r.mask)
+		}
+		redacted = redacted || changed
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return []byte(r.mask)
+	}
+	if !redacted {
+		return body
+	}
+	return buf.Bytes()
+}
+
+// copyValue copies the next JSON value from dec to buf, replacing values
+// whose path matches a rule. It reports whether anything was replaced.
+func (r *redactor) copyValue(dec *json.Decoder, path []pathElem, buf *bytes.Buffer) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+
+	if r.matches(path) {
+		if delim, ok := tok.(json.Delim); ok && (delim == '{' || delim == '[') {
+			if err := skipValue(dec); err != nil {
+				return false, err
+			}
+		}
+		writeJSON(buf, r.mask)
+		return true, nil
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		writeJSON(buf, tok)
+		return false, nil
+	}
+
+	redacted := false
+	switch delim {
+	case '{':
+		buf.WriteByte('{')
+		for i := 0; dec.More(); i++ {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return false, err
+			}
+			key, _ := keyTok.(string)
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, key)
+			buf.WriteByte(':')
+			changed, err := r.copyValue(dec, append(path, pathElem{key: key, index: -1}), buf)
+			if err != nil {
+				return false, err
+			}
+			redacted = redacted || changed
+		}
+		buf.WriteByte('}')
+	case '[':
+		buf.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			changed, err := r.copyValue(dec, append(path, pathElem{index: i}), buf)
+			if err != nil {
+				return false, err
+			}
+			redacted = redacted || changed
+		}
+		buf.WriteByte(']')
+	}
+
+	// Consume the closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return false, err
+	}
+	return redacted, nil
+}
+
+// matches reports whether path matches any of the body rules
+func (r *redactor) matches(path []pathElem) bool {
+	if len(path) == 0 {
+		return false
+	}
+	for _, segments := range r.paths {
+		if matchPath(segments, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskAll returns a slice of the same length as values filled with the mask
+func (r *redactor) maskAll(values []string) []string {
+	masked := make([]string, len(values))
+	for i := range masked {
+		masked[i] = r.mask
+	}
+	return masked
+}
+
+// skipValue consumes the rest of an object or array whose opening
+// delimiter has already been read
+func skipValue(dec *json.Decoder) error {
+	depth := 1
+	for depth > 0 {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+	}
+	return nil
+}
+
+// writeJSON encodes a scalar token without escaping HTML characters
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	if n, ok := v.(json.Number); ok {
+		buf.WriteString(n.String())
+		return
+	}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	// Encode appends a newline
+	buf.Truncate(buf.Len() - 1)
+}
+
+// pathElem is one step into a JSON document, either an object key or an
+// array index (key is empty and index is non-negative)
+type pathElem struct {
+	key   string
+	index int
+}
+
+type segmentKind int
+
+const (
+	segmentKey segmentKind = iota
+	segmentIndex
+	segmentWildcard
+	segmentDescend
+)
+
+// pathSegment is one step of a compiled body path rule
+type pathSegment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+// parsePath compiles a JSONPath-like rule such as $.password, $.card.number,
+// $.items[*].token or $..secret. A bare name like "password" matches the
+// field at any depth.
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		path = "$.." + path
+	}
+	rest := path[1:]
+
+	var segments []pathSegment
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segments = append(segments, pathSegment{kind: segmentDescend})
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				continue
+			}
+			name, remaining := readName(rest)
+			if name == "" {
+				return nil, fmt.Errorf("reqpretty: invalid redaction path %q", path)
+			}
+			segments = append(segments, nameSegment(name))
+			rest = remaining
+		case strings.HasPrefix(rest, "."):
+			name, remaining := readName(rest[1:])
+			if name == "" {
+				return nil, fmt.Errorf("reqpretty: invalid redaction path %q", path)
+			}
+			segments = append(segments, nameSegment(name))
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("reqpretty: invalid redaction path %q", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{kind: segmentWildcard})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{kind: segmentKey, key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("reqpretty: invalid redaction path %q", path)
+				}
+				segments = append(segments, pathSegment{kind: segmentIndex, index: index})
+			}
+		default:
+			return nil, fmt.Errorf("reqpretty: invalid redaction path %q", path)
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("reqpretty: invalid redaction path %q", path)
+	}
+	return segments, nil
+}
+
+// readName reads a dotted name up to the next '.' or '['
+func readName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+func nameSegment(name string) pathSegment {
+	if name == "*" {
+		return pathSegment{kind: segmentWildcard}
+	}
+	return pathSegment{kind: segmentKey, key: name}
+}
+
+// matchPath reports whether the compiled rule matches the full path
+func matchPath(segments []pathSegment, path []pathElem) bool {
+	if len(segments) == 0 {
+		return len(path) == 0
+	}
+	seg := segments[0]
+	if seg.kind == segmentDescend {
+		for i := 0; i <= len(path); i++ {
+			if matchPath(segments[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	elem := path[0]
+	switch seg.kind {
+	case segmentKey:
+		if elem.index >= 0 || elem.key != seg.key {
+			return false
+		}
+	case segmentIndex:
+		if elem.index != seg.index {
+			return false
+		}
+	}
+	return matchPath(segments[1:], path[1:])
+}
@@ -0,0 +1,86 @@
+package reqpretty
+
+import "testing"
+
+func TestParsePath(t *testing.T) {
+	for _, path := range []string{
+		"$",
+		"$.",
+		"$..",
+		"$.card[",
+		"$.items[x]",
+		"$.items[-1]",
+		"$[]",
+		"$card",
+		"",
+	} {
+		if segments, err := parsePath(path); err == nil {
+			t.Errorf("parsePath(%q) = %+v, want an error", path, segments)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	key := func(k string) pathElem { return pathElem{key: k, index: -1} }
+	index := func(i int) pathElem { return pathElem{index: i} }
+
+	tests := []struct {
+		rule  string
+		path  []pathElem
+		match bool
+	}{
+		{"$.password", []pathElem{key("password")}, true},
+		{"$.password", []pathElem{key("user"), key("password")}, false},
+		{"password", []pathElem{key("user"), key("password")}, true},
+		{"password", []pathElem{key("password"), key("hint")}, false},
+		{"$.card.number", []pathElem{key("card"), key("number")}, true},
+		{"$.card.number", []pathElem{key("card")}, false},
+		{"$['card']['number']", []pathElem{key("card"), key("number")}, true},
+		{"$.items[*].token", []pathElem{key("items"), index(3), key("token")}, true},
+		{"$.items[*].token", []pathElem{key("items"), key("x"), key("token")}, true},
+		{"$.items[*].token", []pathElem{key("items"), key("token")}, false},
+		{"$.items.*", []pathElem{key("items"), key("a")}, true},
+		{"$.items[1]", []pathElem{key("items"), index(1)}, true},
+		{"$.items[1]", []pathElem{key("items"), index(2)}, false},
+		{"$.items[1]", []pathElem{key("items"), key("1")}, false},
+		{"$..secret", []pathElem{key("a"), index(0), key("secret")}, true},
+		{"$..[0]", []pathElem{key("a"), index(0)}, true},
+		{"$..secret", []pathElem{key("secrets")}, false},
+	}
+	for _, tt := range tests {
+		segments, err := parsePath(tt.rule)
+		if err != nil {
+			t.Fatalf("parsePath(%q): %v", tt.rule, err)
+		}
+		if got := matchPath(segments, tt.path); got != tt.match {
+			t.Errorf("matchPath(%q, %+v) = %v, want %v", tt.rule, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	r, err := newRedactor(Options{RedactBodyFields: []string{"password"}, RedactMask: "***"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		truncated bool
+		want      string
+	}{
+		{"single document", `{"user":"a","password":"x"}`, false, `{"user":"a","password":"***"}`},
+		{"no match", `{"user":"a"}`, false, `{"user":"a"}`},
+		{"plain text", `password=x`, false, `password=x`},
+		{"ndjson", "{\"password\":\"x\"}\n{\"password\":\"y\"}\n", false, "{\"password\":\"***\"}\n{\"password\":\"***\"}"},
+		{"concatenated", `{"user":"a"}{"password":"y"}`, false, "{\"user\":\"a\"}\n{\"password\":\"***\"}"},
+		{"trailing text", `{"user":"a"} password=y`, false, `***`},
+		{"truncated sequence", "{\"password\":\"x\"}\n{\"password\":\"y", true, "{\"password\":\"***\"}\n{\"password\":"},
+	}
+	for _, tt := range tests {
+		if got := string(r.redactBody([]byte(tt.body), tt.truncated)); got != tt.want {
+			t.Errorf("%s: redactBody(%q) = %q, want %q", tt.name, tt.body, got, tt.want)
+		}
+	}
+}
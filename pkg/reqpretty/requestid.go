@@ -0,0 +1,109 @@
+package reqpretty
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// defaultRequestIDHeader is used when RequestIDHeader is empty
+const defaultRequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key holding the request ID
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID DebugHandler stored in ctx,
+// or an empty string when there is none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewUUID returns a random version 4 UUID
+func NewUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
+
+// crockford is the base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID, a 48-bit millisecond timestamp followed by 80
+// random bits, so IDs sort by creation time
+func NewULID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(b[6:])
+
+	// 128 bits encode to 26 characters of 5 bits, the first holding 3
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// requestIDHeader returns the configured request ID header
+func (l *exchangeLogger) requestIDHeader() string {
+	if l.opts.RequestIDHeader != "" {
+		return l.opts.RequestIDHeader
+	}
+	return defaultRequestIDHeader
+}
+
+// newRequestID generates an ID with the configured generator
+func (l *exchangeLogger) newRequestID() string {
+	if l.opts.RequestIDGenerator != nil {
+		return l.opts.RequestIDGenerator()
+	}
+	return NewUUID()
+}
+
+// assignRequestID reads the request ID from the incoming header or
+// generates one, echoes it in the response header and returns r with the
+// ID stored in its context
+func (l *exchangeLogger) assignRequestID(w http.ResponseWriter, r *http.Request) *http.Request {
+	header := l.requestIDHeader()
+	id := r.Header.Get(header)
+	if id == "" {
+		id = l.newRequestID()
+	}
+	w.Header().Set(header, id)
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+}
+
+// propagateRequestID sets the request ID header of an outgoing request
+// that lacks one, reusing the ID of the request being served when called
+// from a handler
+func (l *exchangeLogger) propagateRequestID(r *http.Request) {
+	header := l.requestIDHeader()
+	if r.Header.Get(header) != "" {
+		return
+	}
+	id := RequestIDFromContext(r.Context())
+	if id == "" {
+		id = l.newRequestID()
+	}
+	r.Header.Set(header, id)
+}
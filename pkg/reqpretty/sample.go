@@ -0,0 +1,156 @@
+package reqpretty
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/1saifj/reqpretty/pkg/printer"
+)
+
+// sampler decides which exchanges are printed once SampleRate or
+// MaxPerSecond is set, and counts what it held back for the summary
+type sampler struct {
+	rate      float64
+	perSecond float64
+	interval  time.Duration
+
+	mu         sync.Mutex
+	tokens     float64
+	refilled   time.Time
+	since      time.Time
+	logged     int
+	suppressed int
+
+	// report prints a summary, timer is armed while suppressed exchanges
+	// wait to be reported
+	report func(printer.Summary)
+	timer  *time.Timer
+}
+
+// newSampler returns nil when every exchange is to be printed
+func newSampler(opts Options) *sampler {
+	sampling := opts.SampleRate > 0 && opts.SampleRate < 1
+	if !sampling && opts.MaxPerSecond <= 0 {
+		return nil
+	}
+	now := time.Now()
+	s := &sampler{
+		rate:      1,
+		perSecond: float64(opts.MaxPerSecond),
+		interval:  opts.SummaryInterval,
+		tokens:    float64(opts.MaxPerSecond),
+		refilled:  now,
+		since:     now,
+		report:    summaryReporter(opts.Printer),
+	}
+	if sampling {
+		s.rate = opts.SampleRate
+	}
+	return s
+}
+
+// allow makes the sampling decision for one exchange, taking a token from
+// the bucket when rate limiting is enabled
+func (s *sampler) allow() bool {
+	if s.rate < 1 && rand.Float64() >= s.rate {
+		return false
+	}
+	if s.perSecond <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.tokens += now.Sub(s.refilled).Seconds() * s.perSecond
+	if s.tokens > s.perSecond {
+		s.tokens = s.perSecond
+	}
+	s.refilled = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+// record counts an exchange. The first suppressed exchange after a summary
+// arms a timer that reports the next one an interval later, so a burst is
+// reported even when no traffic follows it.
+func (s *sampler) record(logged bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if logged {
+		s.logged++
+		return
+	}
+	s.suppressed++
+	if s.interval > 0 && s.timer == nil {
+		s.timer = time.AfterFunc(s.interval, s.flush)
+	}
+}
+
+// flush reports the exchanges counted since the previous summary
+func (s *sampler) flush() {
+	s.mu.Lock()
+	now := time.Now()
+	summary := printer.Summary{
+		Since:      s.since,
+		Duration:   now.Sub(s.since),
+		Logged:     s.logged,
+		Suppressed: s.suppressed,
+	}
+	s.since, s.logged, s.suppressed, s.timer = now, 0, 0, nil
+	s.mu.Unlock()
+
+	s.report(summary)
+}
+
+// summaryReporter prints summaries through p when it supports them and as
+// a slog record otherwise
+func summaryReporter(p printer.Printer) func(printer.Summary) {
+	if sp, ok := p.(printer.SummaryPrinter); ok {
+		return sp.PrintSummary
+	}
+	return func(summary printer.Summary) {
+		slog.Info("Exchanges suppressed by sampling",
+			"suppressed", summary.Suppressed,
+			"logged", summary.Logged,
+			"interval", summary.Duration)
+	}
+}
+
+// alwaysSampled is the decision for loggers without a sampler
+func alwaysSampled() bool { return true }
+
+// sampleOnce returns a sampling decision that is made on first use and then
+// kept, so the streamed events and the final box of an exchange agree
+func (l *exchangeLogger) sampleOnce() func() bool {
+	if l.sampler == nil {
+		return alwaysSampled
+	}
+	var decided, allowed bool
+	return func() bool {
+		if !decided {
+			decided, allowed = true, l.sampler.allow()
+		}
+		return allowed
+	}
+}
+
+// admit reports whether an exchange that passed the filters is printed.
+// Recovered panics always are, errors and slow exchanges bypass sampling
+// when so configured.
+func (l *exchangeLogger) admit(sampled func() bool, status int, failed, panicked bool, duration time.Duration) bool {
+	if l.sampler == nil {
+		return true
+	}
+	allowed := panicked ||
+		(l.opts.AlwaysLogErrors && (failed || status >= 400)) ||
+		(l.opts.SlowThreshold > 0 && duration >= l.opts.SlowThreshold) ||
+		sampled()
+	l.sampler.record(allowed)
+	return allowed
+}
@@ -0,0 +1,229 @@
+package reqpretty
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/1saifj/reqpretty/pkg/printer"
+)
+
+// streamRecorder turns a streamed response into incremental events. A
+// response becomes a stream once it is sent as text/event-stream, in which
+// case every complete event is reported as it is written, or once the
+// handler flushes, in which case every flushed chunk is reported.
+type streamRecorder struct {
+	emit    func(printer.StreamEvent)
+	start   time.Time
+	active  bool
+	sse     bool
+	pending []byte
+	events  int
+	bytes   int64
+
+	// checked is set once the header was sent and its content type looked at
+	checked bool
+
+	// limit caps the bytes kept of each chunk or event, zero keeps
+	// everything, and chunkSize counts the bytes written since the previous
+	// flush
+	limit     int64
+	chunkSize int64
+
+	// kept is set once an unfinished event outgrows limit, pending then
+	// holds its first kept bytes followed by the latest few needed to find
+	// its end, and skipped counts the bytes dropped in between
+	kept    int
+	skipped int64
+
+	// summary is set by finish once the handler has returned
+	summary *printer.StreamSummary
+}
+
+// begin switches the response into streaming mode, announcing the stream
+// and replaying what was written before it was detected: the captured prefix written of
+// size bytes in total
+func (s *streamRecorder) begin(sse bool, written []byte, size int64) {
+	s.active = true
+	s.sse = sse
+	s.send(printer.StreamEvent{Start: true, SSE: sse})
+	s.write(written)
+	if skipped := size - int64(len(written)); skipped > 0 {
+		s.bytes += skipped
+		if !s.sse {
+			s.chunkSize += skipped
+		}
+	}
+}
+
+// write records streamed bytes, reporting every complete SSE event. Only
+// the first limit bytes of a chunk or event are kept.
+func (s *streamRecorder) write(p []byte) {
+	s.bytes += int64(len(p))
+	if s.emit == nil {
+		return
+	}
+	if !s.sse {
+		s.chunkSize += int64(len(p))
+		if s.limit > 0 {
+			p = p[:min(int64(len(p)), max(s.limit-int64(len(s.pending)), 0))]
+		}
+		s.pending = append(s.pending, p...)
+		return
+	}
+	s.pending = append(s.pending, p...)
+	for {
+		last, rest, ok := cutEvent(s.pending[s.kept:])
+		if !ok {
+			break
+		}
+		s.endEvent(last)
+		s.pending = rest
+	}
+	s.capEvent()
+}
+
+// capEvent bounds the unfinished event in pending, keeping its first limit
+// bytes and the last few, enough to spot the blank line that ends it
+func (s *streamRecorder) capEvent() {
+	const window = 3
+	if s.limit <= 0 || s.kept == 0 && int64(len(s.pending)) <= s.limit {
+		return
+	}
+	if s.kept == 0 {
+		s.kept = int(s.limit)
+	}
+	if extra := len(s.pending) - s.kept - window; extra > 0 {
+		s.skipped += int64(extra)
+		s.pending = append(s.pending[:s.kept], s.pending[len(s.pending)-window:]...)
+	}
+}
+
+// endEvent reports the pending event, last being the bytes of it after
+// any that were kept when it outgrew the limit
+func (s *streamRecorder) endEvent(last []byte) {
+	block, size := last, int64(len(last))
+	if s.kept > 0 {
+		block = s.pending[:s.kept]
+		size += int64(s.kept) + s.skipped
+	}
+	s.kept, s.skipped = 0, 0
+	s.emitEvent(block, size)
+}
+
+// flush reports the bytes written since the previous flush as one chunk
+func (s *streamRecorder) flush() {
+	if s.sse || s.chunkSize == 0 {
+		return
+	}
+	s.events++
+	s.send(printer.StreamEvent{
+		Chunk:     s.pending,
+		Size:      s.chunkSize,
+		Truncated: s.chunkSize > int64(len(s.pending)),
+	})
+	s.pending, s.chunkSize = nil, 0
+}
+
+// finish reports whatever is left once the handler returns and summarizes
+// the stream
+func (s *streamRecorder) finish() {
+	if !s.active {
+		return
+	}
+	if s.sse && len(bytes.TrimSpace(s.pending)) > 0 {
+		s.endEvent(s.pending[s.kept:])
+		s.pending = nil
+	}
+	s.flush()
+	s.summary = &printer.StreamSummary{SSE: s.sse, Events: s.events, Bytes: s.bytes}
+}
+
+// emitEvent parses one SSE event block of size bytes and reports it,
+// skipping blocks that only hold comments. Only the first limit bytes of
+// the block are parsed.
+func (s *streamRecorder) emitEvent(block []byte, size int64) {
+	truncated := s.limit > 0 && size > s.limit
+	if truncated {
+		block = block[:min(int64(len(block)), s.limit)]
+	}
+	ev, ok := parseEvent(block)
+	if !ok {
+		return
+	}
+	ev.Size, ev.Truncated = size, truncated
+	s.events++
+	s.send(ev)
+}
+
+func (s *streamRecorder) send(ev printer.StreamEvent) {
+	ev.Seq = s.events
+	ev.Offset = time.Since(s.start)
+	if s.emit != nil {
+		s.emit(ev)
+	}
+}
+
+// isEventStream reports whether the response is sent as text/event-stream
+func isEventStream(h http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// cutEvent splits the first complete event block, terminated by a blank
+// line, from the rest of the stream
+func cutEvent(stream []byte) (block, rest []byte, ok bool) {
+	for i := 0; i < len(stream); i++ {
+		if stream[i] != '\n' && stream[i] != '\r' {
+			continue
+		}
+		// Find the end of this line terminator and check for a second one
+		j := i + 1
+		if stream[i] == '\r' && j < len(stream) && stream[j] == '\n' {
+			j++
+		}
+		if j < len(stream) && (stream[j] == '\n' || stream[j] == '\r') {
+			k := j + 1
+			if stream[j] == '\r' && k < len(stream) && stream[k] == '\n' {
+				k++
+			}
+			return stream[:i], stream[k:], true
+		}
+	}
+	return nil, stream, false
+}
+
+// parseEvent parses the event, id, data and retry fields of an SSE event
+// block. Comment lines and unknown fields are ignored.
+func parseEvent(block []byte) (printer.StreamEvent, bool) {
+	ev := printer.StreamEvent{SSE: true}
+	var data []string
+	hasField := false
+
+	text := strings.ReplaceAll(string(block), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Event = value
+		case "id":
+			ev.ID = value
+		case "retry":
+			ev.Retry = value
+		case "data":
+			data = append(data, value)
+		default:
+			continue
+		}
+		hasField = true
+	}
+	ev.Data = strings.Join(data, "\n")
+	return ev, hasField
+}
@@ -0,0 +1,35 @@
+package reqpretty
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/1saifj/reqpretty/pkg/printer"
+)
+
+func TestStreamRecorderBoundsUnfinishedEvents(t *testing.T) {
+	var events []printer.StreamEvent
+	s := &streamRecorder{limit: 16, emit: func(ev printer.StreamEvent) {
+		if !ev.Start {
+			events = append(events, ev)
+		}
+	}}
+	s.begin(true, nil, 0)
+
+	s.write([]byte("data: "))
+	for i := 0; i < 1000; i++ {
+		s.write(bytes.Repeat([]byte("z"), 100))
+		if len(s.pending) > 16+3 {
+			t.Fatalf("pending grew to %d bytes", len(s.pending))
+		}
+	}
+	s.write([]byte("\r\n\r\n"))
+	s.finish()
+
+	if len(events) != 1 || events[0].Data != "zzzzzzzzzz" || events[0].Size != 100006 || !events[0].Truncated {
+		t.Errorf("unexpected events: %+v", events)
+	}
+	if s.summary == nil || s.summary.Bytes != 100010 {
+		t.Errorf("unexpected summary: %+v", s.summary)
+	}
+}
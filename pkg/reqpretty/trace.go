@@ -0,0 +1,90 @@
+package reqpretty
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/1saifj/reqpretty/pkg/printer"
+)
+
+// clientTrace records the httptrace events of one outbound request
+type clientTrace struct {
+	mu    sync.Mutex
+	start time.Time
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotConn, wroteRequest     time.Time
+	firstByte                 time.Time
+	reused                    bool
+}
+
+// record stores now in t while holding the lock, keeping the first value
+// so retries within one request don't hide the original timing
+func (c *clientTrace) record(t *time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.IsZero() {
+		*t = time.Now()
+	}
+}
+
+func (c *clientTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:     func(httptrace.DNSStartInfo) { c.record(&c.dnsStart) },
+		DNSDone:      func(httptrace.DNSDoneInfo) { c.record(&c.dnsDone) },
+		ConnectStart: func(string, string) { c.record(&c.connectStart) },
+		ConnectDone: func(string, string, error) {
+			// With several addresses the last attempt to finish wins
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.connectDone = time.Now()
+		},
+		TLSHandshakeStart: func() { c.record(&c.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { c.record(&c.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.gotConn.IsZero() {
+				c.gotConn = time.Now()
+				c.reused = info.Reused
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { c.record(&c.wroteRequest) },
+		GotFirstResponseByte: func() { c.record(&c.firstByte) },
+	}
+}
+
+// timing builds the waterfall of phases up to end
+func (c *clientTrace) timing(end time.Time) *printer.Timing {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &printer.Timing{ConnReused: c.reused}
+	add := func(name string, from, to time.Time) {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return
+		}
+		t.Phases = append(t.Phases, printer.TimingPhase{
+			Name:     name,
+			Start:    from.Sub(c.start),
+			Duration: to.Sub(from),
+		})
+	}
+
+	add("DNS lookup", c.dnsStart, c.dnsDone)
+	add("TCP connect", c.connectStart, c.connectDone)
+	add("TLS handshake", c.tlsStart, c.tlsDone)
+	add("Request sent", c.gotConn, c.wroteRequest)
+	add("Server processing", c.wroteRequest, c.firstByte)
+	add("Content transfer", c.firstByte, end)
+
+	if !c.firstByte.IsZero() {
+		t.TimeToFirstByte = c.firstByte.Sub(c.start)
+	}
+	t.Total = end.Sub(c.start)
+	return t
+}
@@ -0,0 +1,62 @@
+package reqpretty
+
+import (
+	"net/http"
+	"strings"
+)
+
+// traceIDs returns the trace and span IDs of r, from the TraceContext hook
+// when it knows the active span and otherwise from the traceparent header,
+// along with the link built from TraceLinkTemplate
+func (l *exchangeLogger) traceIDs(r *http.Request) (traceID, spanID, link string) {
+	ok := false
+	if l.opts.TraceContext != nil {
+		traceID, spanID, ok = l.opts.TraceContext(r.Context())
+	}
+	if !ok {
+		traceID, spanID, ok = parseTraceparent(r.Header.Get("Traceparent"))
+	}
+	if !ok {
+		return "", "", ""
+	}
+	if l.opts.TraceLinkTemplate != "" {
+		link = strings.NewReplacer("{trace_id}", traceID, "{span_id}", spanID).Replace(l.opts.TraceLinkTemplate)
+	}
+	return traceID, spanID, link
+}
+
+// parseTraceparent extracts the trace and parent span IDs from a W3C
+// traceparent header such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func parseTraceparent(h string) (traceID, spanID string, ok bool) {
+	h = strings.TrimSpace(h)
+	// Later versions may append fields after the four known ones
+	if len(h) < 55 || (len(h) > 55 && h[55] != '-') {
+		return "", "", false
+	}
+	version, traceID, spanID, flags := h[0:2], h[3:35], h[36:52], h[53:55]
+	if h[2] != '-' || h[35] != '-' || h[52] != '-' {
+		return "", "", false
+	}
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(h) != 55) {
+		return "", "", false
+	}
+	if !isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flags) {
+		return "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// isLowerHex reports whether s only holds lowercase hex digits
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,200 @@
+package reqpretty
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/1saifj/reqpretty/pkg/printer"
+)
+
+// transport logs outgoing requests and responses around a base RoundTripper
+type transport struct {
+	exchangeLogger
+	base http.RoundTripper
+}
+
+// Transport wraps base with debug logging of outgoing requests, using the
+// same Options and Printer as DebugHandler. A nil base uses
+// http.DefaultTransport. The response body still streams to the caller, the
+// exchange is printed once the body has been read to the end or closed.
+// Upgraded connections (101 Switching Protocols) are printed right away and
+// their body is returned unwrapped. Like DebugHandler, it panics when
+// RedactBodyFields holds an invalid path.
+func Transport(opts Options, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{exchangeLogger: newExchangeLogger(opts), base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.passthrough(req) {
+		return t.base.RoundTrip(req)
+	}
+	startTime := time.Now()
+
+	// Work on a copy, a RoundTripper must not modify the caller's request
+	ctx := req.Context()
+	var trace *clientTrace
+	if t.opts.IncludeTiming {
+		trace = &clientTrace{start: startTime}
+		ctx = httptrace.WithClientTrace(ctx, trace.clientTrace())
+	}
+	out := req.Clone(ctx)
+	if t.opts.RequestID {
+		t.propagateRequestID(out)
+	}
+	var reqBody *requestBody
+	if t.capturesRequestBody() {
+		var err error
+		reqBody, err = captureRequestBody(out, t.opts.MaxRequestBodyBytes, t.opts.StreamRequestBody)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.base.RoundTrip(out)
+	if err != nil {
+		t.printExchange(out, reqBody, nil, nil, startTime, trace, err)
+		return nil, err
+	}
+
+	// Upgraded connections get a writable body the caller type asserts, it
+	// is handed over unwrapped like a hijacked connection on the server
+	_, writable := resp.Body.(io.Writer)
+	if resp.Body == nil || resp.Body == http.NoBody || writable || resp.StatusCode == http.StatusSwitchingProtocols {
+		t.printExchange(out, reqBody, resp, &bodyCapture{}, startTime, trace, nil)
+		return resp, nil
+	}
+
+	body := &responseBody{
+		ReadCloser: resp.Body,
+		capture:    t.responseCapture(),
+		length:     resp.ContentLength,
+	}
+	body.done = func(err error) {
+		t.printExchange(out, reqBody, resp, &body.capture, startTime, trace, err)
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// printExchange prints an outbound exchange that passes the filters, resp
+// is nil and the status is zero when the request failed before a response
+// arrived
+func (t *transport) printExchange(req *http.Request, reqBody *requestBody, resp *http.Response, respBody *bodyCapture, startTime time.Time, trace *clientTrace, err error) {
+	end := time.Now()
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	if !t.shouldLog(req, status, end.Sub(startTime)) || !t.admit(t.sampleOnce(), status, err != nil, false, end.Sub(startTime)) {
+		return
+	}
+
+	e := printer.Exchange{
+		Time:     startTime,
+		Duration: end.Sub(startTime),
+		Outbound: true,
+		Context:  req.Context(),
+	}
+	if t.opts.RequestID {
+		e.RequestID = req.Header.Get(t.requestIDHeader())
+	}
+	if t.opts.IncludeTrace {
+		e.TraceID, e.SpanID, e.TraceURL = t.traceIDs(req)
+	}
+	if trace != nil {
+		e.Timing = trace.timing(end)
+	}
+	e.Request, e.Attrs = t.newRequest(req, reqBody)
+	if resp != nil && t.opts.IncludeResponse {
+		e.Response = t.newResponse(resp.StatusCode, resp.Header, respBody)
+		if respBody.cut {
+			// Closed early, the declared length is the best known size
+			e.Response.BodySize = max(e.Response.BodySize, resp.ContentLength)
+		}
+		e.Response.Upgraded = resp.StatusCode == http.StatusSwitchingProtocols
+	}
+	if err != nil {
+		e.Error = &printer.TransportError{Kind: classifyError(err), Err: err}
+	}
+	t.opts.Printer.PrintExchange(e)
+}
+
+// responseBody records the response body as the caller reads it and reports
+// the exchange once, at the end of the body or when it is closed
+type responseBody struct {
+	io.ReadCloser
+	capture bodyCapture
+	once    sync.Once
+	done    func(err error)
+
+	// length is the declared Content-Length, -1 when unknown
+	length int64
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.capture.Write(p[:n])
+	switch {
+	case err == io.EOF:
+		b.finish(nil)
+	case err != nil:
+		b.capture.cut = true
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		// Closed before the end of the body, what was read is a prefix
+		b.capture.cut = b.length < 0 || b.capture.total < b.length
+		b.done(nil)
+	})
+	return err
+}
+
+func (b *responseBody) finish(err error) {
+	b.once.Do(func() { b.done(err) })
+}
+
+// classifyError gives a short description of why an outbound request failed
+func classifyError(err error) string {
+	var (
+		dnsErr       *net.DNSError
+		certErr      *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		netErr       net.Error
+		opErr        *net.OpError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Request canceled"
+	case errors.As(err, &dnsErr):
+		return "DNS lookup failed"
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return "TLS error"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "Timeout"
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return "Connection failed"
+	default:
+		return "Transport error"
+	}
+}